@@ -0,0 +1,159 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Stable error codes returned in the "code" field of error responses.
+// Clients may rely on these; do not rename them.
+const (
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeValidation           = "VALIDATION_ERROR"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeConflict             = "CONFLICT"
+	CodeVersionConflict      = "VERSION_CONFLICT"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodeFileTooLarge         = "FILE_TOO_LARGE"
+	CodeUnsupportedFileType  = "UNSUPPORTED_FILE_TYPE"
+	CodeStorageLimitExceeded = "STORAGE_LIMIT_EXCEEDED"
+	CodeStorageUnavailable   = "STORAGE_UNAVAILABLE"
+	CodeUploadExpired        = "UPLOAD_EXPIRED"
+	CodeInternal             = "INTERNAL_ERROR"
+)
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is the error type returned by handlers. The ErrorHandler in main.go renders it as
+//
+//	{"error": true, "code": "...", "message": "...", "fields": [...], "request_id": "..."}
+type APIError struct {
+	Status  int                    `json:"-"`
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Fields  []FieldError           `json:"fields,omitempty"`
+	Details map[string]interface{} `json:"-"` // Extra top-level keys, e.g. the current version on conflicts
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// With adds an extra top-level key to the error response
+func (e *APIError) With(key string, value interface{}) *APIError {
+	if e.Details == nil {
+		e.Details = map[string]interface{}{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// New creates an APIError with an explicit status and code
+func New(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+// BadRequest is returned for malformed requests
+func BadRequest(message string) *APIError {
+	return New(fiber.StatusBadRequest, CodeBadRequest, message)
+}
+
+// Validation is returned when one or more fields are invalid
+func Validation(fields []FieldError) *APIError {
+	return &APIError{Status: fiber.StatusBadRequest, Code: CodeValidation, Message: "Validation failed", Fields: fields}
+}
+
+// Unauthorized is returned when authentication is missing or invalid
+func Unauthorized(message string) *APIError {
+	return New(fiber.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden is returned when the user may not perform the action
+func Forbidden(message string) *APIError {
+	return New(fiber.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFound is returned when the addressed resource does not exist
+func NotFound(message string) *APIError {
+	return New(fiber.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict is returned when the request conflicts with the current state of the resource
+func Conflict(message string) *APIError {
+	return New(fiber.StatusConflict, CodeConflict, message)
+}
+
+// PreconditionRequired is returned when a conditional header such as If-Match is missing
+func PreconditionRequired(message string) *APIError {
+	return New(fiber.StatusPreconditionRequired, CodePreconditionRequired, message)
+}
+
+// Internal is returned for unexpected server-side failures
+func Internal(message string) *APIError {
+	return New(fiber.StatusInternalServerError, CodeInternal, message)
+}
+
+// Or returns err when it already is an APIError and fallback otherwise. Use it for helpers
+// that can fail with a client error as well as an unexpected one.
+func Or(err error, fallback *APIError) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return fallback
+}
+
+// fiberCodes maps fiber's built-in errors (404 route not found, 413 body too large, ...) to stable codes
+var fiberCodes = map[int]string{
+	fiber.StatusBadRequest:            CodeBadRequest,
+	fiber.StatusUnauthorized:          CodeUnauthorized,
+	fiber.StatusForbidden:             CodeForbidden,
+	fiber.StatusNotFound:              CodeNotFound,
+	fiber.StatusMethodNotAllowed:      CodeNotFound,
+	fiber.StatusConflict:              CodeConflict,
+	fiber.StatusRequestEntityTooLarge: CodeFileTooLarge,
+	fiber.StatusUnsupportedMediaType:  CodeUnsupportedFileType,
+}
+
+// From converts any error returned by a handler into an APIError
+func From(err error) *APIError {
+	switch e := err.(type) {
+	case *APIError:
+		return e
+	case *fiber.Error:
+		code, ok := fiberCodes[e.Code]
+		if !ok {
+			code = CodeInternal
+		}
+		return New(e.Code, code, e.Message)
+	}
+	return Internal(err.Error())
+}
+
+// Render writes the error response, including the request ID set by the requestid middleware
+func Render(c *fiber.Ctx, err *APIError) error {
+	body := fiber.Map{
+		"error":   true,
+		"code":    err.Code,
+		"message": err.Message,
+	}
+	if len(err.Fields) > 0 {
+		body["fields"] = err.Fields
+	}
+	for key, value := range err.Details {
+		body[key] = value
+	}
+	if requestID, ok := c.Locals("requestid").(string); ok && requestID != "" {
+		body["request_id"] = requestID
+	}
+
+	return c.Status(err.Status).JSON(body)
+}
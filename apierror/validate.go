@@ -0,0 +1,116 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"yaro-wora-be/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// Report fields by their JSON names, matching the request body
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// link: absolute http(s) URL, mailto:/tel: link or site-relative path
+	v.RegisterValidation("link", func(fl validator.FieldLevel) bool {
+		return utils.IsValidURL(fl.Field().String())
+	})
+
+	return v
+}
+
+// Bind parses the request body into dst and validates it against its `validate` tags
+func Bind(c *fiber.Ctx, dst interface{}) error {
+	if err := c.BodyParser(dst); err != nil {
+		return BadRequest("Invalid request body")
+	}
+	return Validate(dst)
+}
+
+// Validate checks a struct against its `validate` tags and returns a VALIDATION_ERROR
+// listing every invalid field, or nil
+func Validate(value interface{}) error {
+	fields := ValidateFields(value)
+	if len(fields) == 0 {
+		return nil
+	}
+	return Validation(fields)
+}
+
+// ValidateFields checks a struct against its `validate` tags and returns the invalid fields
+func ValidateFields(value interface{}) []FieldError {
+	err := validate.Struct(value)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []FieldError{{Field: "", Message: err.Error()}}
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, FieldError{Field: fieldPath(fe), Message: fieldMessage(fe)})
+	}
+	return fields
+}
+
+// fieldPath strips the struct name from the namespace: "LoginRequest.username" -> "username"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fe.Field()
+}
+
+// fieldMessage turns a failed validation tag into a readable message
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if":
+		return "is required"
+	case "link", "url":
+		return "must be a valid URL"
+	case "hexcolor":
+		return "must be a hex colour such as #1A2B3C"
+	case "email":
+		return "must be a valid email address"
+	case "gte":
+		if fe.Param() == "0" {
+			return "must not be negative"
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "lte":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return fmt.Sprintf("must contain %s %s item(s)", bound, fe.Param())
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", bound, fe.Param())
+		}
+		return fmt.Sprintf("must be %s %s", bound, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	}
+	return fmt.Sprintf("failed the %q check", fe.Tag())
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+)
+
+const cliUsage = `Usage:
+  yaro-wora-api                                   Start the API server
+  yaro-wora-api export [-o bundle.json]           Export all content to a JSON bundle (stdout by default)
+  yaro-wora-api import [-strategy skip|overwrite|duplicate] [-dry-run] bundle.json
+                                                  Import a JSON bundle (- reads stdin)
+  yaro-wora-api reindex-media                     Rebuild the index of where media files are used
+`
+
+// runCommand runs a CLI subcommand and returns the process exit code
+func runCommand(args []string) int {
+	var err error
+	switch args[0] {
+	case "export":
+		err = runExport(args[1:])
+	case "import":
+		err = runImport(args[1:])
+	case "reindex-media":
+		err = runReindexMedia()
+	case "help", "-h", "--help":
+		fmt.Print(cliUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", args[0], cliUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runExport writes all content as a bundle to a file or stdout
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "write the bundle to this file instead of stdout")
+	flags.Parse(args)
+
+	bundle, err := models.ExportBundle(config.DB)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return err
+	}
+
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "✅ Exported content (%d images referenced) to %s\n", len(bundle.Images), *output)
+	}
+	return nil
+}
+
+// runImport imports a bundle file and prints the report as JSON
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	strategy := flags.String("strategy", models.ImportStrategySkip, "what to do with items that already exist: skip, overwrite or duplicate")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without changing anything")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("import expects exactly one bundle file")
+	}
+
+	var in io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	var bundle models.Bundle
+	if err := json.NewDecoder(in).Decode(&bundle); err != nil {
+		return fmt.Errorf("invalid bundle: %v", err)
+	}
+
+	report, importErr := models.ImportBundle(config.DB, &bundle, models.ImportOptions{
+		Strategy: *strategy,
+		DryRun:   *dryRun,
+	})
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+	if importErr != nil {
+		return importErr
+	}
+
+	if *dryRun {
+		fmt.Fprintln(os.Stderr, "✅ Dry run finished; nothing was changed")
+	} else {
+		fmt.Fprintln(os.Stderr, "✅ Import finished")
+	}
+	return nil
+}
+
+// runReindexMedia rebuilds media_usages from the content, e.g. after rows were changed by hand
+func runReindexMedia() error {
+	if err := models.RebuildMediaUsages(config.DB); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := config.DB.Model(&models.MediaUsage{}).Count(&count).Error; err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✅ Indexed %d media usages\n", count)
+	return nil
+}
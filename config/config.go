@@ -19,6 +19,11 @@ type Config struct {
 	DBPassword string
 	DBName     string
 
+	// Storage
+	StorageBackend        string // r2, local or memory
+	LocalStorageDir       string // Directory of the local backend
+	LocalStoragePublicURL string // URL the local directory is served at; its path is the static route
+
 	// Cloudflare R2
 	R2AccessKey  string
 	R2SecretKey  string
@@ -31,6 +36,9 @@ type Config struct {
 	Port      string
 	JWTSecret string
 
+	// Preview links
+	PreviewTokenTTLHours int
+
 	// Admin Auth
 	AdminUsername string
 	AdminPassword string
@@ -42,6 +50,28 @@ type Config struct {
 	// Upload
 	MaxFileUploadSize int     // in bytes
 	StorageLimitGB    float64 // in GB
+
+	// Presigned uploads, sent straight to R2 instead of through the API
+	PresignedUploadMaxSize       int // in bytes
+	PresignedUploadExpiryMinutes int
+
+	// Image processing
+	ImageVariants      string // Responsive variants, e.g. "sm:320,md:640,card:400x400"
+	ImageAVIF          bool   // Also encode variants to AVIF (needs avifenc)
+	ImageThumbnailSize int    // in pixels, longest side
+
+	// Upload validation
+	UploadAllowedTypes string // Content types accepted for upload, e.g. "image/png,image/webp"
+	ImageMaxPixels     int    // Largest image accepted, width × height
+
+	// Background jobs
+	PublishSchedulerIntervalSeconds int // 0 disables the scheduler
+	TrashRetentionDays              int // Soft-deleted content older than this is purged, 0 disables auto purge
+	TrashPurgeIntervalMinutes       int
+	MediaGCIntervalMinutes          int  // Orphaned R2 object collection, 0 disables
+	MediaGCGraceHours               int  // Unreferenced objects younger than this are kept (uploads not saved yet)
+	MediaGCDelete                   bool // Scheduled runs delete orphans instead of only reporting them
+	StorageUsageIntervalMinutes     int  // Storage usage reconciliation and daily snapshot, 0 disables
 }
 
 var AppConfig *Config
@@ -61,6 +91,11 @@ func LoadConfig() {
 		DBPassword: getEnv("DB_PASSWORD", "password"),
 		DBName:     getEnv("DB_NAME", "yaro_wora"),
 
+		// Storage
+		StorageBackend:        getEnv("STORAGE_BACKEND", "r2"),
+		LocalStorageDir:       getEnv("LOCAL_STORAGE_DIR", "./storage"),
+		LocalStoragePublicURL: getEnv("LOCAL_STORAGE_PUBLIC_URL", ""),
+
 		// Cloudflare R2
 		R2AccessKey:  getEnv("R2_ACCESS_KEY", ""),
 		R2SecretKey:  getEnv("R2_SECRET_KEY", ""),
@@ -73,6 +108,9 @@ func LoadConfig() {
 		Port:      getEnv("PORT", "3000"),
 		JWTSecret: getEnv("JWT_SECRET", "default-secret-change-this"),
 
+		// Preview links
+		PreviewTokenTTLHours: getEnvAsInt("PREVIEW_TOKEN_TTL_HOURS", 72),
+
 		// Admin Auth
 		AdminUsername: getEnv("ADMIN_USERNAME", "admin"),
 		AdminPassword: getEnv("ADMIN_PASSWORD", "admin123"),
@@ -84,6 +122,32 @@ func LoadConfig() {
 		// Upload - Default 4MB (4 * 1024 * 1024 = 4194304 bytes)
 		MaxFileUploadSize: getEnvAsInt("MAX_FILE_UPLOAD_SIZE_IN_BYTES", 4194304),
 		StorageLimitGB:    getEnvAsFloat("STORAGE_LIMIT_GB", 1.0), // Default 1GB
+
+		// Presigned uploads - Default 50MB
+		PresignedUploadMaxSize:       getEnvAsInt("PRESIGNED_UPLOAD_MAX_SIZE_IN_BYTES", 52428800),
+		PresignedUploadExpiryMinutes: getEnvAsInt("PRESIGNED_UPLOAD_EXPIRY_MINUTES", 15),
+
+		// Image processing
+		ImageVariants:      getEnv("IMAGE_VARIANTS", "sm:320,md:640,lg:1024,xl:1600,card:400x400"),
+		ImageAVIF:          getEnvAsBool("IMAGE_AVIF", false),
+		ImageThumbnailSize: getEnvAsInt("IMAGE_THUMBNAIL_SIZE", 320),
+
+		// Upload validation - Default 50 megapixels
+		UploadAllowedTypes: getEnv("UPLOAD_ALLOWED_TYPES", "image/jpeg,image/png,image/webp,image/gif,image/svg+xml,video/mp4,video/webm"),
+		ImageMaxPixels:     getEnvAsInt("IMAGE_MAX_PIXELS", 50000000),
+
+		// Background jobs
+		PublishSchedulerIntervalSeconds: getEnvAsInt("PUBLISH_SCHEDULER_INTERVAL_SECONDS", 60),
+		TrashRetentionDays:              getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+		TrashPurgeIntervalMinutes:       getEnvAsInt("TRASH_PURGE_INTERVAL_MINUTES", 60),
+		MediaGCIntervalMinutes:          getEnvAsInt("MEDIA_GC_INTERVAL_MINUTES", 1440),
+		MediaGCGraceHours:               getEnvAsInt("MEDIA_GC_GRACE_HOURS", 24),
+		MediaGCDelete:                   getEnvAsBool("MEDIA_GC_DELETE", false),
+		StorageUsageIntervalMinutes:     getEnvAsInt("STORAGE_USAGE_INTERVAL_MINUTES", 60),
+	}
+
+	if AppConfig.LocalStoragePublicURL == "" {
+		AppConfig.LocalStoragePublicURL = fmt.Sprintf("http://localhost:%s/storage", AppConfig.Port)
 	}
 }
 
@@ -112,6 +176,15 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func ConnectDatabase() {
 	dsn := fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
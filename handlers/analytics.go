@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 	"yaro-wora-be/utils"
@@ -16,16 +17,23 @@ import (
 // ANALYTICS - ADMIN
 // =============================================================================
 
-// GetStorageAnalytics returns storage usage analytics
+// GetStorageAnalytics returns storage usage analytics from the running usage totals.
+// ?refresh=true lists the whole bucket first.
 func GetStorageAnalytics(c *fiber.Ctx) error {
+	if err := requireStorage(); err != nil {
+		return err
+	}
+
+	if c.Query("refresh") == "true" {
+		if _, err := utils.Storage.ReconcileUsage(); err != nil {
+			return apierror.Internal("Failed to refresh storage usage: " + err.Error())
+		}
+	}
+
 	// Get storage analytics
 	analytics, err := utils.Storage.GetStorageAnalytics()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to get storage analytics: " + err.Error(),
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to get storage analytics: " + err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -34,6 +42,29 @@ func GetStorageAnalytics(c *fiber.Ctx) error {
 	})
 }
 
+// GetStorageUsageHistory returns the daily storage usage snapshots of the last ?days= days
+// (default 90), oldest first, for the usage growth chart
+func GetStorageUsageHistory(c *fiber.Ctx) error {
+	days := 90
+	if daysStr := c.Query("days"); daysStr != "" {
+		if parsedDays, err := strconv.Atoi(daysStr); err == nil && parsedDays > 0 {
+			days = parsedDays
+		}
+	}
+	startDate := time.Now().AddDate(0, 0, -days)
+
+	var snapshots []models.StorageUsageSnapshot
+	if err := config.DB.Where("date >= ?", startDate.Format("2006-01-02")).Order("date ASC").Find(&snapshots).Error; err != nil {
+		return apierror.Internal("Failed to fetch storage usage history")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    snapshots,
+		"period":  fmt.Sprintf("Last %d days", days),
+	})
+}
+
 // TrackVisitor tracks a new visitor visit
 func TrackVisitor(c *fiber.Ctx) error {
 	// Get visitor information from request
@@ -74,11 +105,7 @@ func TrackVisitor(c *fiber.Ctx) error {
 	}
 
 	if err := config.DB.Create(&visitor).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to track visitor: " + err.Error(),
-			"code":    "TRACKING_ERROR",
-		})
+		return apierror.New(fiber.StatusInternalServerError, "TRACKING_ERROR", "Failed to track visitor: "+err.Error())
 	}
 
 	return c.JSON(fiber.Map{
@@ -1,15 +1,42 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
-	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
 )
 
+// AttractionRequest is the body of POST /attractions and PUT /attractions/:id
+type AttractionRequest struct {
+	models.Publication
+	Title         string         `json:"title" validate:"required"`
+	TitleID       string         `json:"title_id" validate:"required"`
+	Subtitle      string         `json:"subtitle"`
+	SubtitleID    string         `json:"subtitle_id"`
+	Description   string         `json:"description"`
+	DescriptionID string         `json:"description_id"`
+	ImageURL      string         `json:"image_url" validate:"omitempty,link"`
+	Highlights    datatypes.JSON `json:"highlights"`
+	HighlightsID  datatypes.JSON `json:"highlights_id"`
+	SortOrder     int            `json:"sort_order" validate:"gte=0"`
+	Active        bool           `json:"active"`
+}
+
+// GeneralAttractionContentRequest is the body of PUT /attraction-content
+type GeneralAttractionContentRequest struct {
+	AttractionSectionTitlePart1    string `json:"attraction_section_title_part_1"`
+	AttractionSectionTitlePart1ID  string `json:"attraction_section_title_part_1_id"`
+	AttractionSectionTitlePart2    string `json:"attraction_section_title_part_2"`
+	AttractionSectionTitlePart2ID  string `json:"attraction_section_title_part_2_id"`
+	AttractionSectionDescription   string `json:"attraction_section_description"`
+	AttractionSectionDescriptionID string `json:"attraction_section_description_id"`
+}
+
 // =============================================================================
 // ATTRACTION MANAGEMENT - ADMIN
 // =============================================================================
@@ -17,20 +44,17 @@ import (
 // CreateAttraction creates a new attraction
 func CreateAttraction(c *fiber.Ctx) error {
 	var attraction models.Attraction
-	if err := c.BodyParser(&attraction); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req AttractionRequest
+	if err := bindRequest(c, &req, &attraction); err != nil {
+		return err
+	}
+
+	if err := attraction.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
 	if err := config.DB.Create(&attraction).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create attraction",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create attraction")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(attraction)
@@ -42,27 +66,22 @@ func UpdateAttraction(c *fiber.Ctx) error {
 
 	var attraction models.Attraction
 	if err := config.DB.Where("id = ?", id).First(&attraction).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Attraction not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Attraction not found")
 	}
 
-	if err := c.BodyParser(&attraction); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(attraction)
+
+	var req AttractionRequest
+	if err := bindRequest(c, &req, &attraction); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&attraction).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update attraction",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := attraction.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "attractions", attraction.ID, before, &attraction); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update attraction"))
 	}
 
 	return c.JSON(attraction)
@@ -72,32 +91,15 @@ func UpdateAttraction(c *fiber.Ctx) error {
 func DeleteAttraction(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the attraction to retrieve URLs for R2 deletion
+	// Make sure the attraction exists
 	var attraction models.Attraction
 	if err := config.DB.Where("id = ?", id).First(&attraction).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Attraction not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Attraction not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Where("id = ?", id).Delete(&models.Attraction{}).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete attraction",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image
-		if err := utils.Storage.DeleteImageIfR2(attraction.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete attraction")
 	}
 
 	return c.JSON(fiber.Map{
@@ -108,37 +110,27 @@ func DeleteAttraction(c *fiber.Ctx) error {
 
 // UpdateGeneralAttractionContent updates the general attraction content (singleton)
 func UpdateGeneralAttractionContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.GeneralAttractionContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req GeneralAttractionContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.GeneralAttractionContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create general attraction content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create general attraction content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update general attraction content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "attraction-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update general attraction content"))
 	}
 
 	return c.JSON(content)
@@ -151,7 +143,7 @@ func UpdateGeneralAttractionContent(c *fiber.Ctx) error {
 // GetAttractions returns all attractions with optional filtering
 func GetAttractions(c *fiber.Ctx) error {
 	var attractions []models.Attraction
-	query := config.DB.Model(&models.Attraction{})
+	query := config.DB.Model(&models.Attraction{}).Scopes(publicationScope(c))
 
 	// Apply featured filter if specified
 	if active := c.Query("active"); active == "true" {
@@ -166,16 +158,12 @@ func GetAttractions(c *fiber.Ctx) error {
 	}
 
 	if err := query.Order("sort_order ASC, created_at ASC").Find(&attractions).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch attractions data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch attractions data")
 	}
 
 	// Count active attractions
 	var activeCount int64
-	config.DB.Model(&models.Attraction{}).Where("active = ?", true).Count(&activeCount)
+	config.DB.Model(&models.Attraction{}).Scopes(publicationScope(c)).Where("active = ?", true).Count(&activeCount)
 
 	return c.JSON(fiber.Map{
 		"data": attractions,
@@ -195,6 +183,7 @@ func GetGeneralAttractionContent(c *fiber.Ctx) error {
 			"data": models.GeneralAttractionContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 	"yaro-wora-be/utils"
@@ -17,41 +18,25 @@ type LoginRequest struct {
 // Login handles admin login
 func Login(c *fiber.Ctx) error {
 	var req LoginRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	if err := apierror.Bind(c, &req); err != nil {
+		return err
 	}
 
 	// Find user by username
 	var user models.User
 	if err := config.DB.Where("username = ? AND is_active = ?", req.Username, true).First(&user).Error; err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid credentials",
-			"code":    "UNAUTHORIZED",
-		})
+		return apierror.Unauthorized("Invalid credentials")
 	}
 
 	// Check password
 	if !user.CheckPassword(req.Password) {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid credentials",
-			"code":    "UNAUTHORIZED",
-		})
+		return apierror.Unauthorized("Invalid credentials")
 	}
 
 	// Generate JWT token
 	token, err := utils.GenerateJWT(user.Username, user.ID, user.Role)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to generate token",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to generate token")
 	}
 
 	return c.JSON(utils.SimpleAuthResponse{
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Bulk actions
+const (
+	BulkActionDelete         = "delete"
+	BulkActionPublish        = "publish"
+	BulkActionUnpublish      = "unpublish"
+	BulkActionChangeCategory = "change_category"
+	BulkActionAddTags        = "add_tags"
+	BulkActionRemoveTags     = "remove_tags"
+)
+
+// BulkRequest is the body of the bulk endpoints
+type BulkRequest struct {
+	Action     string   `json:"action" validate:"required,oneof=delete publish unpublish change_category add_tags remove_tags"`
+	IDs        []uint   `json:"ids" validate:"required,min=1"`
+	Atomic     bool     `json:"atomic"`      // Roll back every item when one fails
+	Permanent  bool     `json:"permanent"`   // delete: skip the trash and remove images from R2
+	CategoryID uint     `json:"category_id"` // change_category
+	Tags       []string `json:"tags"`        // add_tags / remove_tags
+	TagsID     []string `json:"tags_id"`     // add_tags / remove_tags (Indonesian tags, gallery only)
+}
+
+// BulkItemResult reports the outcome of a bulk action for a single item
+type BulkItemResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// bulkCategoryTypes maps content types to the content type of their categories
+var bulkCategoryTypes = map[string]string{
+	"destinations": "destination-categories",
+	"gallery":      "gallery-categories",
+	"regulations":  "regulation-categories",
+	"facilities":   "facility-categories",
+	"news":         "news-categories",
+}
+
+// bulkTagColumns maps content types to their tag columns (EN first, then ID)
+var bulkTagColumns = map[string][]string{
+	"gallery": {"tags", "tags_id"},
+	"news":    {"tags"},
+}
+
+var errBulkAborted = errors.New("bulk action aborted")
+
+// =============================================================================
+// BULK MANAGEMENT - ADMIN
+// =============================================================================
+
+// BulkCarousel runs a bulk action on carousel slides
+func BulkCarousel(c *fiber.Ctx) error {
+	return bulkContent(c, "carousel")
+}
+
+// BulkWhyVisit runs a bulk action on why visit items
+func BulkWhyVisit(c *fiber.Ctx) error {
+	return bulkContent(c, "why-visit")
+}
+
+// BulkSellingPoints runs a bulk action on selling points
+func BulkSellingPoints(c *fiber.Ctx) error {
+	return bulkContent(c, "selling-points")
+}
+
+// BulkAttractions runs a bulk action on attractions
+func BulkAttractions(c *fiber.Ctx) error {
+	return bulkContent(c, "attractions")
+}
+
+// BulkDestinations runs a bulk action on destinations
+func BulkDestinations(c *fiber.Ctx) error {
+	return bulkContent(c, "destinations")
+}
+
+// BulkGallery runs a bulk action on gallery images
+func BulkGallery(c *fiber.Ctx) error {
+	return bulkContent(c, "gallery")
+}
+
+// BulkRegulations runs a bulk action on regulations
+func BulkRegulations(c *fiber.Ctx) error {
+	return bulkContent(c, "regulations")
+}
+
+// BulkFacilities runs a bulk action on facilities
+func BulkFacilities(c *fiber.Ctx) error {
+	return bulkContent(c, "facilities")
+}
+
+// BulkNews runs a bulk action on news articles
+func BulkNews(c *fiber.Ctx) error {
+	return bulkContent(c, "news")
+}
+
+// BulkHeritage runs a bulk action on heritage items
+func BulkHeritage(c *fiber.Ctx) error {
+	return bulkContent(c, "heritage")
+}
+
+// bulkContent applies one action to a list of items in a single transaction. Every item runs
+// in its own savepoint so a failing item does not undo the others, unless the request is atomic.
+// Images of permanently deleted items are removed from R2 only after the commit.
+func bulkContent(c *fiber.Ctx, contentType string) error {
+	var req BulkRequest
+	if err := apierror.Bind(c, &req); err != nil {
+		return err
+	}
+
+	ct, _ := models.LookupContentType(contentType)
+
+	switch req.Action {
+	case BulkActionDelete, BulkActionPublish, BulkActionUnpublish:
+	case BulkActionChangeCategory:
+		categoryType, ok := bulkCategoryTypes[contentType]
+		if !ok {
+			return apierror.BadRequest(ct.Name + " has no categories")
+		}
+		categoryCT, _ := models.LookupContentType(categoryType)
+		if err := config.DB.Where("id = ?", req.CategoryID).First(categoryCT.New()).Error; err != nil {
+			return apierror.BadRequest("Category not found")
+		}
+	case BulkActionAddTags, BulkActionRemoveTags:
+		if _, ok := bulkTagColumns[contentType]; !ok {
+			return apierror.BadRequest(ct.Name + " has no tags")
+		}
+		if len(req.Tags) == 0 && len(req.TagsID) == 0 {
+			return apierror.BadRequest("tags or tags_id is required")
+		}
+	default:
+		return apierror.BadRequest(fmt.Sprintf("Unknown action %q", req.Action))
+	}
+
+	results := make([]BulkItemResult, 0, len(req.IDs))
+	var purged []interface{}
+	failed := 0
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Session(&gorm.Session{SkipHooks: true})
+		for _, id := range req.IDs {
+			var row interface{}
+			itemErr := tx.Transaction(func(itemTx *gorm.DB) error {
+				var err error
+				row, err = applyBulkAction(itemTx, c, ct, id, req)
+				return err
+			})
+
+			if itemErr != nil {
+				if errors.Is(itemErr, gorm.ErrRecordNotFound) {
+					itemErr = errors.New("not found")
+				}
+				results = append(results, BulkItemResult{ID: id, Error: itemErr.Error()})
+				failed++
+				continue
+			}
+
+			results = append(results, BulkItemResult{ID: id, Success: true})
+			if row != nil {
+				purged = append(purged, row)
+			}
+		}
+
+		if req.Atomic && failed > 0 {
+			return errBulkAborted
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBulkAborted) {
+		return apierror.BadRequest("Bulk action rolled back because some items failed").With("data", results)
+	}
+	if err != nil {
+		return apierror.Internal("Failed to run bulk action")
+	}
+
+	// Only touch R2 once the rows are gone for good
+	models.DeleteUnreferencedImages(config.DB, purged...)
+
+	return c.JSON(fiber.Map{
+		"success": failed == 0,
+		"data":    results,
+		"meta": fiber.Map{
+			"action":    req.Action,
+			"total":     len(results),
+			"succeeded": len(results) - failed,
+			"failed":    failed,
+		},
+	})
+}
+
+// applyBulkAction runs the action for one item. It returns the deleted row when the item
+// was permanently deleted so its images can be cleaned up after the commit. Other changes bump
+// the version and record a revision in the item's savepoint, as a single update does.
+func applyBulkAction(tx *gorm.DB, c *fiber.Ctx, ct models.ContentType, id uint, req BulkRequest) (interface{}, error) {
+	row := ct.New()
+	query := tx
+	if req.Action == BulkActionDelete && req.Permanent {
+		// Permanent deletes may also empty items that are already in the trash
+		query = tx.Unscoped()
+	}
+	if err := query.Where("id = ?", id).First(row).Error; err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	switch req.Action {
+	case BulkActionDelete:
+		if req.Permanent {
+			return row, tx.Unscoped().Delete(row).Error
+		}
+		return nil, tx.Delete(row).Error
+
+	case BulkActionPublish:
+		var publication models.Publication
+		if err := tx.Model(ct.New()).Select("status, publish_at, unpublish_at").Where("id = ?", id).Scan(&publication).Error; err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		updates["status"] = models.StatusPublished
+		if publication.PublishAt == nil || publication.PublishAt.After(now) {
+			updates["publish_at"] = now
+		}
+		if publication.UnpublishAt != nil && !publication.UnpublishAt.After(now) {
+			updates["unpublish_at"] = nil
+		}
+
+	case BulkActionUnpublish:
+		updates["status"] = models.StatusDraft
+
+	case BulkActionChangeCategory:
+		updates["category_id"] = req.CategoryID
+
+	case BulkActionAddTags, BulkActionRemoveTags:
+		columns := bulkTagColumns[ct.Key]
+		changes := [][]string{req.Tags, req.TagsID}
+		for i, column := range columns {
+			if len(changes[i]) == 0 {
+				continue
+			}
+			var current datatypes.JSON
+			if err := tx.Model(ct.New()).Select(column).Where("id = ?", id).Scan(&current).Error; err != nil {
+				return nil, err
+			}
+			tags, err := updateTags(current, changes[i], req.Action == BulkActionAddTags)
+			if err != nil {
+				return nil, err
+			}
+			updates[column] = tags
+		}
+	}
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	before := revisionSnapshot(row)
+	updates["version"] = gorm.Expr("version + 1")
+	if err := tx.Model(ct.New()).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	row = ct.New()
+	if err := tx.Where("id = ?", id).First(row).Error; err != nil {
+		return nil, err
+	}
+	return nil, recordRevision(tx, c, ct.Key, id, before, models.RevisionActionUpdate, row)
+}
+
+// updateTags adds or removes tags from a JSON array of strings, keeping the existing order
+func updateTags(current datatypes.JSON, changes []string, add bool) (datatypes.JSON, error) {
+	var tags []string
+	if len(current) > 0 && string(current) != "null" {
+		if err := json.Unmarshal(current, &tags); err != nil {
+			return nil, fmt.Errorf("invalid tags: %v", err)
+		}
+	}
+
+	present := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		present[tag] = true
+	}
+
+	if add {
+		for _, tag := range changes {
+			if tag != "" && !present[tag] {
+				tags = append(tags, tag)
+				present[tag] = true
+			}
+		}
+	} else {
+		remove := make(map[string]bool, len(changes))
+		for _, tag := range changes {
+			remove[tag] = true
+		}
+		kept := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			if !remove[tag] {
+				kept = append(kept, tag)
+			}
+		}
+		tags = kept
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
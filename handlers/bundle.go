@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportBundleQuery represents the query parameters of a bundle import
+type ImportBundleQuery struct {
+	Strategy string `query:"strategy" json:"strategy" validate:"omitempty,oneof=skip overwrite duplicate"`
+	DryRun   bool   `query:"dry_run" json:"dry_run"`
+}
+
+// =============================================================================
+// BUNDLE IMPORT/EXPORT - ADMIN
+// =============================================================================
+
+// ExportBundle downloads all content as a JSON bundle that can be imported into another environment
+func ExportBundle(c *fiber.Ctx) error {
+	bundle, err := models.ExportBundle(config.DB)
+	if err != nil {
+		return apierror.Internal("Failed to export content")
+	}
+
+	filename := fmt.Sprintf("yaro-wora-bundle-%s.json", bundle.ExportedAt.Format("20060102-150405"))
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSON(bundle)
+}
+
+// ImportBundle imports a JSON bundle produced by ExportBundle.
+// Use ?strategy=skip|overwrite|duplicate (default skip) and ?dry_run=true to preview the result.
+func ImportBundle(c *fiber.Ctx) error {
+	var query ImportBundleQuery
+	if err := c.QueryParser(&query); err != nil {
+		return apierror.BadRequest("Invalid query parameters")
+	}
+	if err := apierror.Validate(&query); err != nil {
+		return err
+	}
+	if query.Strategy == "" {
+		query.Strategy = models.ImportStrategySkip
+	}
+
+	var bundle models.Bundle
+	if err := json.Unmarshal(c.Body(), &bundle); err != nil {
+		return apierror.BadRequest("Invalid bundle")
+	}
+
+	report, err := models.ImportBundle(config.DB, &bundle, models.ImportOptions{
+		Strategy: query.Strategy,
+		DryRun:   query.DryRun,
+	})
+	switch {
+	case errors.Is(err, models.ErrInvalidBundle):
+		return apierror.BadRequest(err.Error())
+	case errors.Is(err, models.ErrImportFailed):
+		return apierror.New(fiber.StatusUnprocessableEntity, apierror.CodeValidation,
+			"Some items could not be imported; nothing was changed").With("data", report)
+	case err != nil:
+		return apierror.Internal("Failed to import content")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    report,
+	})
+}
@@ -1,14 +1,29 @@
 package handlers
 
 import (
-	"fmt"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
-	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// CarouselRequest is the body of POST /carousel and PUT /carousel/:id
+type CarouselRequest struct {
+	models.Publication
+	Title         string `json:"title" validate:"required"`
+	TitleID       string `json:"title_id" validate:"required"`
+	Subtitle      string `json:"subtitle"`
+	SubtitleID    string `json:"subtitle_id"`
+	ImageURL      string `json:"image_url" validate:"required,link"`
+	ThumbnailURL  string `json:"thumbnail_url" validate:"omitempty,link"`
+	AltText       string `json:"alt_text"`
+	AltTextID     string `json:"alt_text_id"`
+	CarouselOrder int    `json:"carousel_order" validate:"gte=0"`
+	IsActive      bool   `json:"is_active"`
+}
+
 // =============================================================================
 // CAROUSEL MANAGEMENT - ADMIN
 // =============================================================================
@@ -16,20 +31,17 @@ import (
 // CreateCarousel creates a new carousel slide
 func CreateCarousel(c *fiber.Ctx) error {
 	var carousel models.Carousel
-	if err := c.BodyParser(&carousel); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req CarouselRequest
+	if err := bindRequest(c, &req, &carousel); err != nil {
+		return err
+	}
+
+	if err := carousel.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
 	if err := config.DB.Create(&carousel).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create carousel slide",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create carousel slide")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(carousel)
@@ -41,27 +53,22 @@ func UpdateCarousel(c *fiber.Ctx) error {
 
 	var carousel models.Carousel
 	if err := config.DB.Where("id = ?", id).First(&carousel).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Carousel slide not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Carousel slide not found")
+	}
+
+	before := revisionSnapshot(carousel)
+
+	var req CarouselRequest
+	if err := bindRequest(c, &req, &carousel); err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&carousel); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	if err := carousel.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
-	if err := config.DB.Save(&carousel).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update carousel slide",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "carousel", carousel.ID, before, &carousel); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update carousel slide"))
 	}
 
 	return c.JSON(carousel)
@@ -71,32 +78,15 @@ func UpdateCarousel(c *fiber.Ctx) error {
 func DeleteCarousel(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the carousel to retrieve URLs for R2 deletion
+	// Make sure the carousel exists
 	var carousel models.Carousel
 	if err := config.DB.Where("id = ?", id).First(&carousel).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Carousel slide not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Carousel slide not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Delete(&models.Carousel{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete carousel slide",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image
-		if err := utils.Storage.DeleteImageWithThumbnailIfR2(carousel.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete carousel slide")
 	}
 
 	return c.JSON(fiber.Map{
@@ -113,12 +103,18 @@ func DeleteCarousel(c *fiber.Ctx) error {
 func GetCarousel(c *fiber.Ctx) error {
 	var carousels []models.Carousel
 
-	if err := config.DB.Where("is_active = ?", true).Order("carousel_order ASC, created_at ASC").Find(&carousels).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch carousel data",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := config.DB.Scopes(publicationScope(c)).Where("is_active = ?", true).Order("carousel_order ASC, created_at ASC").Find(&carousels).Error; err != nil {
+		return apierror.Internal("Failed to fetch carousel data")
+	}
+
+	// Focal points and variants of the slide images
+	imageURLs := make([]string, len(carousels))
+	for i, carousel := range carousels {
+		imageURLs[i] = carousel.ImageURL
+	}
+	imageMeta := models.LookupImageMeta(config.DB, imageURLs...)
+	for i := range carousels {
+		carousels[i].ImageMeta = imageMeta[carousels[i].ImageURL]
 	}
 
 	return c.JSON(fiber.Map{
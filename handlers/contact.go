@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 
@@ -9,43 +10,57 @@ import (
 	"gorm.io/datatypes"
 )
 
+// ContactInfoRequest is the body of PUT /contact-info
+type ContactInfoRequest struct {
+	AddressPart1     string         `json:"address_part_1"`
+	AddressPart1ID   string         `json:"address_part_1_id"`
+	AddressPart2     string         `json:"address_part_2"`
+	AddressPart2ID   string         `json:"address_part_2_id"`
+	Latitude         float64        `json:"latitude"`
+	Longitude        float64        `json:"longitude"`
+	Phones           datatypes.JSON `json:"phones"`
+	Emails           datatypes.JSON `json:"emails"`
+	SocialMedia      datatypes.JSON `json:"social_media"`
+	PlanYourVisitURL string         `json:"plan_your_visit_url" validate:"omitempty,link"`
+}
+
+// ContactContentRequest is the body of PUT /contact-content
+type ContactContentRequest struct {
+	ContactSectionTitlePart1    string `json:"contact_section_title_part_1"`
+	ContactSectionTitlePart1ID  string `json:"contact_section_title_part_1_id"`
+	ContactSectionTitlePart2    string `json:"contact_section_title_part_2"`
+	ContactSectionTitlePart2ID  string `json:"contact_section_title_part_2_id"`
+	ContactSectionDescription   string `json:"contact_section_description"`
+	ContactSectionDescriptionID string `json:"contact_section_description_id"`
+}
+
 // =============================================================================
 // CONTACT MANAGEMENT - ADMIN
 // =============================================================================
 
 // UpdateContactInfo updates the contact info (singleton)
 func UpdateContactInfo(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.ContactInfo
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req ContactInfoRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.ContactInfo
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create contact info",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create contact info")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update contact info",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "contact-info", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update contact info"))
 	}
 
 	return c.JSON(content)
@@ -53,37 +68,27 @@ func UpdateContactInfo(c *fiber.Ctx) error {
 
 // UpdateContactContent updates the contact content (singleton)
 func UpdateContactContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.ContactContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req ContactContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.ContactContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create general contact content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create general contact content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update general contact content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "contact-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update general contact content"))
 	}
 	return c.JSON(content)
 }
@@ -122,6 +127,7 @@ func GetContactInfo(c *fiber.Ctx) error {
 		})
 	}
 
+	setETag(c, contactInfo.Version)
 	return c.JSON(fiber.Map{
 		"data": contactInfo,
 	})
@@ -136,6 +142,7 @@ func GetGeneralContactContent(c *fiber.Ctx) error {
 			"data": models.ContactContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -1,16 +1,72 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
-	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
 )
 
+// DestinationRequest is the body of POST /destinations and PUT /destinations/:id
+type DestinationRequest struct {
+	models.Publication
+	models.Slugs
+	Title                     string         `json:"title" validate:"required"`
+	TitleID                   string         `json:"title_id" validate:"required"`
+	ShortDescription          string         `json:"short_description"`
+	ShortDescriptionID        string         `json:"short_description_id"`
+	About                     string         `json:"about"`
+	AboutID                   string         `json:"about_id"`
+	ImageURL                  string         `json:"image_url" validate:"omitempty,link"`
+	ThumbnailURL              string         `json:"thumbnail_url" validate:"omitempty,link"`
+	DestinationDetailSections datatypes.JSON `json:"destination_detail_sections"`
+	Highlights                datatypes.JSON `json:"highlights"`
+	HighlightsID              datatypes.JSON `json:"highlights_id"`
+	CTAUrl                    string         `json:"cta_url" validate:"omitempty,link"`
+	GoogleMapsURL             string         `json:"google_maps_url" validate:"omitempty,link"`
+	IsFeatured                bool           `json:"is_featured"`
+	SortOrder                 int            `json:"sort_order" validate:"gte=0"`
+	CategoryID                uint           `json:"category_id" validate:"required"`
+}
+
+// DestinationPageContentRequest is the body of PUT /destinations/content
+type DestinationPageContentRequest struct {
+	HeroImageURL                     string `json:"hero_image_url" validate:"omitempty,link"`
+	HeroImageThumbnailURL            string `json:"hero_image_thumbnail_url" validate:"omitempty,link"`
+	Title                            string `json:"title"`
+	TitleID                          string `json:"title_id"`
+	Subtitle                         string `json:"subtitle"`
+	SubtitleID                       string `json:"subtitle_id"`
+	FeaturedDestinationTitle         string `json:"featured_destination_title"`
+	FeaturedDestinationTitleID       string `json:"featured_destination_title_id"`
+	FeaturedDestinationDescription   string `json:"featured_destination_description"`
+	FeaturedDestinationDescriptionID string `json:"featured_destination_description_id"`
+	OtherDestinationsTitle           string `json:"other_destinations_title"`
+	OtherDestinationsTitleID         string `json:"other_destinations_title_id"`
+	OtherDestinationsDescription     string `json:"other_destinations_description"`
+	OtherDestinationsDescriptionID   string `json:"other_destinations_description_id"`
+	CTATitle                         string `json:"cta_title"`
+	CTATitleID                       string `json:"cta_title_id"`
+	CTADescription                   string `json:"cta_description"`
+	CTADescriptionID                 string `json:"cta_description_id"`
+	CTAButtonText                    string `json:"cta_button_text"`
+	CTAButtonTextID                  string `json:"cta_button_text_id"`
+	CTAButtonURL                     string `json:"cta_button_url" validate:"omitempty,link"`
+}
+
+// DestinationCategoryRequest is the body of POST /destinations/categories and PUT /destinations/categories/:id
+type DestinationCategoryRequest struct {
+	Name          string `json:"name" validate:"required"`
+	NameID        string `json:"name_id" validate:"required"`
+	Description   string `json:"description"`
+	DescriptionID string `json:"description_id"`
+}
+
 // =============================================================================
 // DESTINATION MANAGEMENT - ADMIN
 // =============================================================================
@@ -18,20 +74,17 @@ import (
 // CreateDestination creates a new destination
 func CreateDestination(c *fiber.Ctx) error {
 	var destination models.Destination
-	if err := c.BodyParser(&destination); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req DestinationRequest
+	if err := bindRequest(c, &req, &destination); err != nil {
+		return err
 	}
 
-	if err := config.DB.Create(&destination).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create destination",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := destination.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := models.RetryOnSlugConflict(func() error { return config.DB.Create(&destination).Error }); err != nil {
+		return apierror.Internal("Failed to create destination")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(destination)
@@ -43,27 +96,22 @@ func UpdateDestination(c *fiber.Ctx) error {
 
 	var destination models.Destination
 	if err := config.DB.Where("id = ?", id).First(&destination).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Destination not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Destination not found")
 	}
 
-	if err := c.BodyParser(&destination); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(destination)
+
+	var req DestinationRequest
+	if err := bindRequest(c, &req, &destination); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&destination).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update destination",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := destination.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "destinations", destination.ID, before, &destination); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update destination"))
 	}
 
 	return c.JSON(destination)
@@ -73,38 +121,15 @@ func UpdateDestination(c *fiber.Ctx) error {
 func DeleteDestination(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the destination to retrieve URLs for R2 deletion
+	// Make sure the destination exists
 	var destination models.Destination
 	if err := config.DB.Where("id = ?", id).First(&destination).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Destination not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Destination not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Delete(&models.Destination{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete destination",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image
-		if err := utils.Storage.DeleteImageWithThumbnailIfR2(destination.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
-
-		// Delete images from detail sections
-		if err := utils.Storage.DeleteImagesFromDetailSections(string(destination.DestinationDetailSections)); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete images from destination detail sections: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete destination")
 	}
 
 	return c.JSON(fiber.Map{
@@ -115,37 +140,27 @@ func DeleteDestination(c *fiber.Ctx) error {
 
 // UpdateDestinationPageContent updates the destination page content (singleton)
 func UpdateDestinationPageContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.DestinationPageContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req DestinationPageContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.DestinationPageContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create destination page content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create destination page content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update destination page content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "destination-page-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update destination page content"))
 	}
 
 	return c.JSON(content)
@@ -158,20 +173,13 @@ func UpdateDestinationPageContent(c *fiber.Ctx) error {
 // CreateDestinationCategory creates a new destination category
 func CreateDestinationCategory(c *fiber.Ctx) error {
 	var category models.DestinationCategory
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req DestinationCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
 	if err := config.DB.Create(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create destination category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create destination category")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(category)
@@ -183,27 +191,18 @@ func UpdateDestinationCategory(c *fiber.Ctx) error {
 
 	var category models.DestinationCategory
 	if err := config.DB.Where("id = ?", id).First(&category).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Destination category not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Destination category not found")
 	}
 
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(category)
+
+	var req DestinationCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update destination category",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "destination-categories", category.ID, before, &category); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update destination category"))
 	}
 
 	return c.JSON(category)
@@ -214,11 +213,7 @@ func DeleteDestinationCategory(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.DestinationCategory{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete destination category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete destination category")
 	}
 
 	return c.JSON(fiber.Map{
@@ -235,7 +230,8 @@ func DeleteDestinationCategory(c *fiber.Ctx) error {
 func GetDestinations(c *fiber.Ctx) error {
 	var destinations []models.Destination
 	query := config.DB.Model(&models.Destination{}).
-		Select("id, title, title_id, short_description, short_description_id, image_url, thumbnail_url, highlights, highlights_id, is_featured, sort_order, category_id").
+		Scopes(publicationScope(c)).
+		Select("id, title, title_id, short_description, short_description_id, image_url, thumbnail_url, highlights, highlights_id, is_featured, sort_order, category_id, status, publish_at, unpublish_at, slug, slug_id").
 		Preload("DestinationCategory")
 
 	if isFeatured := c.Query("featured"); isFeatured == "true" {
@@ -277,12 +273,15 @@ func GetDestinations(c *fiber.Ctx) error {
 	query = query.Limit(limit).Offset(offset)
 
 	if err := query.Order("sort_order ASC, created_at ASC, is_featured DESC").Find(&destinations).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch destinations data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch destinations data")
+	}
+
+	// Placeholders, focal points and variants of the images
+	imageURLs := make([]string, len(destinations))
+	for i, dest := range destinations {
+		imageURLs[i] = dest.ImageURL
 	}
+	imageMeta := models.LookupImageMeta(config.DB, imageURLs...)
 
 	// Convert to summary format
 	destinationSummaries := make([]models.DestinationSummary, len(destinations))
@@ -301,12 +300,15 @@ func GetDestinations(c *fiber.Ctx) error {
 			SortOrder:           dest.SortOrder,
 			CategoryID:          dest.CategoryID,
 			DestinationCategory: dest.DestinationCategory,
+			Publication:         dest.Publication,
+			Slugs:               dest.Slugs,
+			ImageMeta:           imageMeta[dest.ImageURL],
 		}
 	}
 
 	// Get total count (apply same filters)
 	var total int64
-	countQuery := config.DB.Model(&models.Destination{})
+	countQuery := config.DB.Model(&models.Destination{}).Scopes(publicationScope(c))
 	if isFeatured := c.Query("featured"); isFeatured == "true" {
 		countQuery = countQuery.Where("is_featured = ?", true)
 	}
@@ -358,19 +360,20 @@ func GetDestinations(c *fiber.Ctx) error {
 	})
 }
 
-// GetDestinationByID returns a specific destination by ID
+// GetDestinationByID returns a specific destination by ID or slug
 func GetDestinationByID(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, redirectTo := resolveDetailParam(c, "destinations")
+	if redirectTo != "" {
+		return c.Redirect(redirectTo, fiber.StatusMovedPermanently)
+	}
 
 	var destination models.Destination
-	if err := config.DB.Preload("DestinationCategory").Where("id = ?", id).First(&destination).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Destination not found",
-			"code":    "NOT_FOUND",
-		})
+	if err := config.DB.Preload("DestinationCategory").Where("id = ?", id).First(&destination).Error; err != nil || !canViewContent(c, "destinations", destination.ID, destination.Publication) {
+		return apierror.NotFound("Destination not found")
 	}
 
+	destination.ImageMeta = models.LookupImageMeta(config.DB, destination.ImageURL)[destination.ImageURL]
+	setETag(c, destination.Version)
 	return c.JSON(fiber.Map{
 		"data": destination,
 	})
@@ -381,7 +384,7 @@ func GetDestinationCategories(c *fiber.Ctx) error {
 	categories := getDestinationCategoriesFromTable()
 
 	var totalDestinations int64
-	config.DB.Model(&models.Destination{}).Count(&totalDestinations)
+	config.DB.Model(&models.Destination{}).Scopes(models.Published).Count(&totalDestinations)
 
 	return c.JSON(fiber.Map{
 		"data": categories,
@@ -401,6 +404,8 @@ func GetDestinationPageContent(c *fiber.Ctx) error {
 			"data": models.DestinationPageContent{},
 		})
 	}
+	content.HeroImageMeta = models.LookupImageMeta(config.DB, content.HeroImageURL)[content.HeroImageURL]
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -416,7 +421,7 @@ func getDestinationCategoriesFromTable() []fiber.Map {
 	categories := make([]fiber.Map, 0, len(catRows))
 	for _, cat := range catRows {
 		var count int64
-		config.DB.Model(&models.Destination{}).Where("category_id = ? AND is_featured = ?", cat.ID, false).Count(&count)
+		config.DB.Model(&models.Destination{}).Scopes(models.Published).Where("category_id = ? AND is_featured = ?", cat.ID, false).Count(&count)
 
 		categories = append(categories, fiber.Map{
 			"id":             cat.ID,
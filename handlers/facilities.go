@@ -1,16 +1,73 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
-	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
 )
 
+// FacilityRequest is the body of POST /facilities and PUT /facilities/:id
+type FacilityRequest struct {
+	models.Publication
+	models.Slugs
+	Name                   string         `json:"name" validate:"required"`
+	NameID                 string         `json:"name_id" validate:"required"`
+	ShortDescription       string         `json:"short_description"`
+	ShortDescriptionID     string         `json:"short_description_id"`
+	Description            string         `json:"description"`
+	DescriptionID          string         `json:"description_id"`
+	ImageURL               string         `json:"image_url" validate:"omitempty,link"`
+	ThumbnailURL           string         `json:"thumbnail_url" validate:"omitempty,link"`
+	CategoryID             uint           `json:"category_id" validate:"required"`
+	FacilityDetailSections datatypes.JSON `json:"facility_detail_sections"`
+	Highlights             datatypes.JSON `json:"highlights"`
+	HighlightsID           datatypes.JSON `json:"highlights_id"`
+	Duration               string         `json:"duration"`
+	Capacity               string         `json:"capacity"`
+	Price                  string         `json:"price"`
+	DurationID             string         `json:"duration_id"`
+	CapacityID             string         `json:"capacity_id"`
+	PriceID                string         `json:"price_id"`
+	CTAUrl                 string         `json:"cta_url" validate:"omitempty,link"`
+	IsFeatured             bool           `json:"is_featured"`
+	SortOrder              int            `json:"sort_order" validate:"gte=0"`
+}
+
+// FacilityPageContentRequest is the body of PUT /facilities/content
+type FacilityPageContentRequest struct {
+	HeroImageURL                       string `json:"hero_image_url" validate:"omitempty,link"`
+	HeroImageThumbnailURL              string `json:"hero_image_thumbnail_url" validate:"omitempty,link"`
+	Title                              string `json:"title"`
+	TitleID                            string `json:"title_id"`
+	Subtitle                           string `json:"subtitle"`
+	SubtitleID                         string `json:"subtitle_id"`
+	FacilitiesListSectionTitle         string `json:"facilities_list_section_title"`
+	FacilitiesListSectionTitleID       string `json:"facilities_list_section_title_id"`
+	FacilitiesListSectionDescription   string `json:"facilities_list_section_description"`
+	FacilitiesListSectionDescriptionID string `json:"facilities_list_section_description_id"`
+	CTATitle                           string `json:"cta_title"`
+	CTATitleID                         string `json:"cta_title_id"`
+	CTADescription                     string `json:"cta_description"`
+	CTADescriptionID                   string `json:"cta_description_id"`
+	CTAButtonText                      string `json:"cta_button_text"`
+	CTAButtonTextID                    string `json:"cta_button_text_id"`
+	CTAButtonURL                       string `json:"cta_button_url" validate:"omitempty,link"`
+}
+
+// FacilityCategoryRequest is the body of POST /facilities/categories and PUT /facilities/categories/:id
+type FacilityCategoryRequest struct {
+	Name          string `json:"name" validate:"required"`
+	NameID        string `json:"name_id" validate:"required"`
+	Description   string `json:"description"`
+	DescriptionID string `json:"description_id"`
+}
+
 // =============================================================================
 // FACILITY MANAGEMENT - ADMIN
 // =============================================================================
@@ -18,20 +75,17 @@ import (
 // CreateFacility creates a new facility
 func CreateFacility(c *fiber.Ctx) error {
 	var facility models.Facility
-	if err := c.BodyParser(&facility); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req FacilityRequest
+	if err := bindRequest(c, &req, &facility); err != nil {
+		return err
 	}
 
-	if err := config.DB.Create(&facility).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create facility",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := facility.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := models.RetryOnSlugConflict(func() error { return config.DB.Create(&facility).Error }); err != nil {
+		return apierror.Internal("Failed to create facility")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(facility)
@@ -43,27 +97,22 @@ func UpdateFacility(c *fiber.Ctx) error {
 
 	var facility models.Facility
 	if err := config.DB.Where("id = ?", id).First(&facility).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Facility not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Facility not found")
 	}
 
-	if err := c.BodyParser(&facility); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(facility)
+
+	var req FacilityRequest
+	if err := bindRequest(c, &req, &facility); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&facility).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update facility",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := facility.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "facilities", facility.ID, before, &facility); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update facility"))
 	}
 
 	return c.JSON(facility)
@@ -73,38 +122,15 @@ func UpdateFacility(c *fiber.Ctx) error {
 func DeleteFacility(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the facility to retrieve URLs for R2 deletion
+	// Make sure the facility exists
 	var facility models.Facility
 	if err := config.DB.Where("id = ?", id).First(&facility).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Facility not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Facility not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Delete(&models.Facility{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete facility",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image and thumbnail
-		if err := utils.Storage.DeleteImageWithThumbnailIfR2(facility.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
-
-		// Delete images from detail sections
-		if err := utils.Storage.DeleteImagesFromDetailSections(string(facility.FacilityDetailSections)); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete images from facility detail sections: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete facility")
 	}
 
 	return c.JSON(fiber.Map{
@@ -115,37 +141,27 @@ func DeleteFacility(c *fiber.Ctx) error {
 
 // UpdateFacilityPageContent updates the facility page content (singleton)
 func UpdateFacilityPageContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.FacilityPageContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req FacilityPageContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.FacilityPageContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create facility page content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create facility page content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update facility page content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "facility-page-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update facility page content"))
 	}
 
 	return c.JSON(content)
@@ -158,20 +174,13 @@ func UpdateFacilityPageContent(c *fiber.Ctx) error {
 // CreateFacilityCategory creates a new facility category
 func CreateFacilityCategory(c *fiber.Ctx) error {
 	var category models.FacilityCategory
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req FacilityCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
 	if err := config.DB.Create(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create facility category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create facility category")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(category)
@@ -183,27 +192,18 @@ func UpdateFacilityCategory(c *fiber.Ctx) error {
 
 	var category models.FacilityCategory
 	if err := config.DB.Where("id = ?", id).First(&category).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Facility category not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Facility category not found")
 	}
 
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(category)
+
+	var req FacilityCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update facility category",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "facility-categories", category.ID, before, &category); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update facility category"))
 	}
 
 	return c.JSON(category)
@@ -214,11 +214,7 @@ func DeleteFacilityCategory(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.FacilityCategory{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete facility category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete facility category")
 	}
 
 	return c.JSON(fiber.Map{
@@ -235,7 +231,8 @@ func DeleteFacilityCategory(c *fiber.Ctx) error {
 func GetFacilities(c *fiber.Ctx) error {
 	var facilities []models.Facility
 	query := config.DB.Model(&models.Facility{}).
-		Select("id, name, name_id, short_description, short_description_id, image_url, thumbnail_url, highlights, highlights_id, is_featured, sort_order, category_id, duration, capacity, price").
+		Scopes(publicationScope(c)).
+		Select("id, name, name_id, short_description, short_description_id, image_url, thumbnail_url, highlights, highlights_id, is_featured, sort_order, category_id, duration, capacity, price, status, publish_at, unpublish_at, slug, slug_id").
 		Preload("FacilityCategory")
 
 	if isFeatured := c.Query("featured"); isFeatured == "true" {
@@ -277,12 +274,15 @@ func GetFacilities(c *fiber.Ctx) error {
 	query = query.Limit(limit).Offset(offset)
 
 	if err := query.Order("sort_order ASC, created_at ASC, is_featured DESC").Find(&facilities).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch facilities data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch facilities data")
+	}
+
+	// Placeholders, focal points and variants of the images
+	imageURLs := make([]string, len(facilities))
+	for i, facility := range facilities {
+		imageURLs[i] = facility.ImageURL
 	}
+	imageMeta := models.LookupImageMeta(config.DB, imageURLs...)
 
 	// Convert to summary format
 	facilitySummaries := make([]models.FacilitySummary, len(facilities))
@@ -304,12 +304,15 @@ func GetFacilities(c *fiber.Ctx) error {
 			Duration:           facility.Duration,
 			Capacity:           facility.Capacity,
 			Price:              facility.Price,
+			Publication:        facility.Publication,
+			Slugs:              facility.Slugs,
+			ImageMeta:          imageMeta[facility.ImageURL],
 		}
 	}
 
 	// Get total count (apply same filters)
 	var total int64
-	countQuery := config.DB.Model(&models.Facility{})
+	countQuery := config.DB.Model(&models.Facility{}).Scopes(publicationScope(c))
 	if isFeatured := c.Query("featured"); isFeatured == "true" {
 		countQuery = countQuery.Where("is_featured = ?", true)
 	}
@@ -361,19 +364,20 @@ func GetFacilities(c *fiber.Ctx) error {
 	})
 }
 
-// GetFacilityByID returns a specific facility by ID
+// GetFacilityByID returns a specific facility by ID or slug
 func GetFacilityByID(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, redirectTo := resolveDetailParam(c, "facilities")
+	if redirectTo != "" {
+		return c.Redirect(redirectTo, fiber.StatusMovedPermanently)
+	}
 
 	var facility models.Facility
-	if err := config.DB.Preload("FacilityCategory").Where("id = ?", id).First(&facility).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Facility not found",
-			"code":    "NOT_FOUND",
-		})
+	if err := config.DB.Preload("FacilityCategory").Where("id = ?", id).First(&facility).Error; err != nil || !canViewContent(c, "facilities", facility.ID, facility.Publication) {
+		return apierror.NotFound("Facility not found")
 	}
 
+	facility.ImageMeta = models.LookupImageMeta(config.DB, facility.ImageURL)[facility.ImageURL]
+	setETag(c, facility.Version)
 	return c.JSON(fiber.Map{
 		"data": facility,
 	})
@@ -384,7 +388,7 @@ func GetFacilityCategories(c *fiber.Ctx) error {
 	categories := getFacilityCategoriesFromTable()
 
 	var totalFacilities int64
-	config.DB.Model(&models.Facility{}).Count(&totalFacilities)
+	config.DB.Model(&models.Facility{}).Scopes(models.Published).Count(&totalFacilities)
 
 	return c.JSON(fiber.Map{
 		"data": categories,
@@ -404,6 +408,7 @@ func GetFacilityPageContent(c *fiber.Ctx) error {
 			"data": models.FacilityPageContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -419,7 +424,7 @@ func getFacilityCategoriesFromTable() []fiber.Map {
 	categories := make([]fiber.Map, 0, len(catRows))
 	for _, cat := range catRows {
 		var count int64
-		config.DB.Model(&models.Facility{}).Where("category_id = ? AND is_featured = ?", cat.ID, false).Count(&count)
+		config.DB.Model(&models.Facility{}).Scopes(models.Published).Where("category_id = ? AND is_featured = ?", cat.ID, false).Count(&count)
 
 		categories = append(categories, fiber.Map{
 			"id":             cat.ID,
@@ -1,53 +1,87 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
+// GalleryImageRequest is the body of POST /gallery and PUT /gallery/:id
+type GalleryImageRequest struct {
+	models.Publication
+	models.Slugs
+	Title              string         `json:"title" validate:"required"`
+	TitleID            string         `json:"title_id" validate:"required"`
+	ShortDescription   string         `json:"short_description"`
+	ShortDescriptionID string         `json:"short_description_id"`
+	Description        string         `json:"description"`
+	DescriptionID      string         `json:"description_id"`
+	ImageURL           string         `json:"image_url" validate:"required,link"`
+	ThumbnailURL       string         `json:"thumbnail_url" validate:"omitempty,link"`
+	CategoryID         uint           `json:"category_id" validate:"required"`
+	Photographer       string         `json:"photographer"`
+	Location           string         `json:"location"`
+	Tags               datatypes.JSON `json:"tags"`
+	TagsID             datatypes.JSON `json:"tags_id"`
+	DateUploaded       time.Time      `json:"date_uploaded"`
+	MediaType          string         `json:"media_type" validate:"omitempty,oneof=image video panorama"`
+	VideoURL           string         `json:"video_url" validate:"required_if=MediaType video,omitempty,link"`
+	Duration           float64        `json:"duration_seconds" validate:"gte=0"`
+}
+
+// GalleryPageContentRequest is the body of PUT /gallery/content
+type GalleryPageContentRequest struct {
+	HeroImageURL          string `json:"hero_image_url" validate:"omitempty,link"`
+	HeroImageThumbnailURL string `json:"hero_image_thumbnail_url" validate:"omitempty,link"`
+	Title                 string `json:"title"`
+	TitleID               string `json:"title_id"`
+	Subtitle              string `json:"subtitle"`
+	SubtitleID            string `json:"subtitle_id"`
+}
+
+// GalleryCategoryRequest is the body of POST /gallery/categories and PUT /gallery/categories/:id
+type GalleryCategoryRequest struct {
+	Name          string `json:"name" validate:"required"`
+	NameID        string `json:"name_id" validate:"required"`
+	Description   string `json:"description"`
+	DescriptionID string `json:"description_id"`
+}
+
 // =============================================================================
 // GALLERY MANAGEMENT - ADMIN
 // =============================================================================
 
 // UpdateGalleryPageContent updates the gallery page content (singleton)
 func UpdateGalleryPageContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.GalleryPageContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req GalleryPageContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.GalleryPageContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create gallery page content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create gallery page content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update gallery page content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "gallery-page-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update gallery page content"))
 	}
 
 	return c.JSON(content)
@@ -56,20 +90,13 @@ func UpdateGalleryPageContent(c *fiber.Ctx) error {
 // CreateGalleryCategory creates a new gallery category
 func CreateGalleryCategory(c *fiber.Ctx) error {
 	var category models.GalleryCategory
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req GalleryCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
 	if err := config.DB.Create(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create gallery category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create gallery category")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(category)
@@ -81,27 +108,18 @@ func UpdateGalleryCategory(c *fiber.Ctx) error {
 
 	var category models.GalleryCategory
 	if err := config.DB.Where("id = ?", id).First(&category).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Gallery category not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Gallery category not found")
 	}
 
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(category)
+
+	var req GalleryCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update gallery category",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "gallery-categories", category.ID, before, &category); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update gallery category"))
 	}
 
 	return c.JSON(category)
@@ -112,11 +130,7 @@ func DeleteGalleryCategory(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.GalleryCategory{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete gallery category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete gallery category")
 	}
 
 	return c.JSON(fiber.Map{
@@ -128,52 +142,67 @@ func DeleteGalleryCategory(c *fiber.Ctx) error {
 // CreateGalleryImage creates a new gallery image
 func CreateGalleryImage(c *fiber.Ctx) error {
 	var image models.GalleryImage
-	if err := c.BodyParser(&image); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req GalleryImageRequest
+	if err := bindRequest(c, &req, &image); err != nil {
+		return err
 	}
 
-	if err := config.DB.Create(&image).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create gallery image",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := image.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	prefillFromCaptureData(config.DB, &image)
+
+	if err := models.RetryOnSlugConflict(func() error { return config.DB.Create(&image).Error }); err != nil {
+		return apierror.Internal("Failed to create gallery image")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(image)
 }
 
+// prefillFromCaptureData fills an empty date, photographer and location of a new gallery image
+// from the EXIF data recorded when its photo was uploaded to the media library, and the
+// duration of a video from its upload
+func prefillFromCaptureData(db *gorm.DB, image *models.GalleryImage) {
+	var asset models.MediaAsset
+	if err := db.Where("url = ?", image.ImageURL).First(&asset).Error; err == nil {
+		asset.PrefillGalleryImage(image)
+	}
+
+	if image.VideoURL == "" {
+		return
+	}
+	if image.MediaType == "" {
+		image.MediaType = utils.MediaTypeVideo
+	}
+	var video models.MediaAsset
+	if image.Duration == 0 && db.Where("url = ?", image.VideoURL).First(&video).Error == nil {
+		image.Duration = video.Duration
+	}
+}
+
 // UpdateGalleryImage updates an existing gallery image
 func UpdateGalleryImage(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	var image models.GalleryImage
 	if err := config.DB.Where("id = ?", id).First(&image).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Gallery image not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Gallery image not found")
 	}
 
-	if err := c.BodyParser(&image); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(image)
+
+	var req GalleryImageRequest
+	if err := bindRequest(c, &req, &image); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&image).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update gallery image",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := image.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "gallery", image.ID, before, &image); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update gallery image"))
 	}
 
 	return c.JSON(image)
@@ -183,32 +212,15 @@ func UpdateGalleryImage(c *fiber.Ctx) error {
 func DeleteGalleryImage(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the image to retrieve URLs for R2 deletion
+	// Make sure the image exists
 	var image models.GalleryImage
 	if err := config.DB.Where("id = ?", id).First(&image).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Gallery image not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Gallery image not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Delete(&models.GalleryImage{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete gallery image",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image
-		if err := utils.Storage.DeleteImageWithThumbnailIfR2(image.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete gallery image")
 	}
 
 	return c.JSON(fiber.Map{
@@ -230,6 +242,8 @@ func GetGalleryPageContent(c *fiber.Ctx) error {
 			"data": models.GalleryPageContent{},
 		})
 	}
+	content.HeroImageMeta = models.LookupImageMeta(config.DB, content.HeroImageURL)[content.HeroImageURL]
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -239,7 +253,8 @@ func GetGalleryPageContent(c *fiber.Ctx) error {
 func GetGallery(c *fiber.Ctx) error {
 	var images []models.GalleryImage
 	query := config.DB.Model(&models.GalleryImage{}).
-		Select("id, title, title_id, short_description, short_description_id, image_url, thumbnail_url, category_id, date_uploaded").
+		Scopes(publicationScope(c)).
+		Select("id, title, title_id, short_description, short_description_id, image_url, thumbnail_url, category_id, date_uploaded, media_type, video_url, duration, status, publish_at, unpublish_at, slug, slug_id").
 		Preload("GalleryCategory")
 
 	// Apply category filter (supports comma-separated list)
@@ -258,6 +273,11 @@ func GetGallery(c *fiber.Ctx) error {
 		}
 	}
 
+	// Apply media type filter: image, video or panorama
+	if mediaType := c.Query("media_type"); mediaType != "" {
+		query = query.Where("media_type = ?", mediaType)
+	}
+
 	// Apply limit and offset for pagination
 	limit := 12
 	if l := c.Query("limit"); l != "" {
@@ -276,12 +296,15 @@ func GetGallery(c *fiber.Ctx) error {
 	query = query.Limit(limit).Offset(offset)
 
 	if err := query.Order("date_uploaded DESC, created_at DESC").Find(&images).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch gallery data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch gallery data")
+	}
+
+	// Placeholders, focal points and variants of the images
+	imageURLs := make([]string, len(images))
+	for i, img := range images {
+		imageURLs[i] = img.ImageURL
 	}
+	imageMeta := models.LookupImageMeta(config.DB, imageURLs...)
 
 	// Convert to summary format
 	imageSummaries := make([]models.GalleryImageSummary, len(images))
@@ -297,12 +320,18 @@ func GetGallery(c *fiber.Ctx) error {
 			CategoryID:         img.CategoryID,
 			GalleryCategory:    img.GalleryCategory,
 			DateUploaded:       img.DateUploaded,
+			MediaType:          img.MediaType,
+			VideoURL:           img.VideoURL,
+			Duration:           img.Duration,
+			Publication:        img.Publication,
+			Slugs:              img.Slugs,
+			ImageMeta:          imageMeta[img.ImageURL],
 		}
 	}
 
 	// Get total count (apply same filters)
 	var total int64
-	countQuery := config.DB.Model(&models.GalleryImage{})
+	countQuery := config.DB.Model(&models.GalleryImage{}).Scopes(publicationScope(c))
 	if category := c.Query("category"); category != "" {
 		cats := make([]string, 0)
 		for _, part := range strings.Split(category, ",") {
@@ -317,6 +346,9 @@ func GetGallery(c *fiber.Ctx) error {
 			countQuery = countQuery.Where("category_id IN ?", cats)
 		}
 	}
+	if mediaType := c.Query("media_type"); mediaType != "" {
+		countQuery = countQuery.Where("media_type = ?", mediaType)
+	}
 	countQuery.Count(&total)
 
 	// Get categories with counts from GalleryCategory table
@@ -356,7 +388,7 @@ func GetGalleryCategories(c *fiber.Ctx) error {
 	categories := getGalleryCategoriesFromTable()
 
 	var totalImages int64
-	config.DB.Model(&models.GalleryImage{}).Count(&totalImages)
+	config.DB.Model(&models.GalleryImage{}).Scopes(models.Published).Count(&totalImages)
 
 	return c.JSON(fiber.Map{
 		"data": categories,
@@ -367,19 +399,20 @@ func GetGalleryCategories(c *fiber.Ctx) error {
 	})
 }
 
-// GetGalleryImageByID returns a specific gallery image by ID
+// GetGalleryImageByID returns a specific gallery image by ID or slug
 func GetGalleryImageByID(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, redirectTo := resolveDetailParam(c, "gallery")
+	if redirectTo != "" {
+		return c.Redirect(redirectTo, fiber.StatusMovedPermanently)
+	}
 
 	var image models.GalleryImage
-	if err := config.DB.Preload("GalleryCategory").Where("id = ?", id).First(&image).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Gallery image not found",
-			"code":    "NOT_FOUND",
-		})
+	if err := config.DB.Preload("GalleryCategory").Where("id = ?", id).First(&image).Error; err != nil || !canViewContent(c, "gallery", image.ID, image.Publication) {
+		return apierror.NotFound("Gallery image not found")
 	}
 
+	image.ImageMeta = models.LookupImageMeta(config.DB, image.ImageURL)[image.ImageURL]
+	setETag(c, image.Version)
 	return c.JSON(fiber.Map{
 		"data": image,
 	})
@@ -395,7 +428,7 @@ func getGalleryCategoriesFromTable() []fiber.Map {
 	categories := make([]fiber.Map, 0, len(catRows))
 	for _, cat := range catRows {
 		var count int64
-		config.DB.Model(&models.GalleryImage{}).Where("category_id = ?", cat.ID).Count(&count)
+		config.DB.Model(&models.GalleryImage{}).Scopes(models.Published).Where("category_id = ?", cat.ID).Count(&count)
 
 		categories = append(categories, fiber.Map{
 			"id":             cat.ID,
@@ -1,15 +1,53 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
-	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
 )
 
+// HeritageRequest is the body of POST /heritage and PUT /heritage/:id
+type HeritageRequest struct {
+	models.Publication
+	models.Slugs
+	Title                  string         `json:"title" validate:"required"`
+	TitleID                string         `json:"title_id" validate:"required"`
+	ShortDescription       string         `json:"short_description"`
+	ShortDescriptionID     string         `json:"short_description_id"`
+	Description            string         `json:"description"`
+	DescriptionID          string         `json:"description_id"`
+	ImageURL               string         `json:"image_url" validate:"omitempty,link"`
+	ThumbnailURL           string         `json:"thumbnail_url" validate:"omitempty,link"`
+	HeritageDetailSections datatypes.JSON `json:"heritage_detail_sections"`
+	SortOrder              int            `json:"sort_order" validate:"gte=0"`
+}
+
+// HeritagePageContentRequest is the body of PUT /heritage/content
+type HeritagePageContentRequest struct {
+	HeroImageURL             string `json:"hero_image_url" validate:"omitempty,link"`
+	HeroImageThumbnailURL    string `json:"hero_image_thumbnail_url" validate:"omitempty,link"`
+	Title                    string `json:"title"`
+	TitleID                  string `json:"title_id"`
+	Subtitle                 string `json:"subtitle"`
+	SubtitleID               string `json:"subtitle_id"`
+	MainSectionTitle         string `json:"main_section_title"`
+	MainSectionTitleID       string `json:"main_section_title_id"`
+	MainSectionDescription   string `json:"main_section_description"`
+	MainSectionDescriptionID string `json:"main_section_description_id"`
+	CTATitle                 string `json:"cta_title"`
+	CTATitleID               string `json:"cta_title_id"`
+	CTADescription           string `json:"cta_description"`
+	CTADescriptionID         string `json:"cta_description_id"`
+	CTAButtonText            string `json:"cta_button_text"`
+	CTAButtonTextID          string `json:"cta_button_text_id"`
+	CTAButtonURL             string `json:"cta_button_url" validate:"omitempty,link"`
+}
+
 // =============================================================================
 // HERITAGE MANAGEMENT - ADMIN
 // =============================================================================
@@ -17,20 +55,17 @@ import (
 // CreateHeritage creates a new heritage
 func CreateHeritage(c *fiber.Ctx) error {
 	var heritage models.Heritage
-	if err := c.BodyParser(&heritage); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req HeritageRequest
+	if err := bindRequest(c, &req, &heritage); err != nil {
+		return err
 	}
 
-	if err := config.DB.Create(&heritage).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create heritage",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := heritage.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := models.RetryOnSlugConflict(func() error { return config.DB.Create(&heritage).Error }); err != nil {
+		return apierror.Internal("Failed to create heritage")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(heritage)
@@ -42,27 +77,22 @@ func UpdateHeritage(c *fiber.Ctx) error {
 
 	var heritage models.Heritage
 	if err := config.DB.Where("id = ?", id).First(&heritage).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Heritage not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Heritage not found")
 	}
 
-	if err := c.BodyParser(&heritage); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(heritage)
+
+	var req HeritageRequest
+	if err := bindRequest(c, &req, &heritage); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&heritage).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update heritage",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := heritage.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "heritage", heritage.ID, before, &heritage); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update heritage"))
 	}
 
 	return c.JSON(heritage)
@@ -72,38 +102,15 @@ func UpdateHeritage(c *fiber.Ctx) error {
 func DeleteHeritage(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the heritage to retrieve URLs for R2 deletion
+	// Make sure the heritage exists
 	var heritage models.Heritage
 	if err := config.DB.Where("id = ?", id).First(&heritage).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Heritage not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Heritage not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Delete(&models.Heritage{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete heritage",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image and thumbnail
-		if err := utils.Storage.DeleteImageWithThumbnailIfR2(heritage.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
-
-		// Delete images from detail sections
-		if err := utils.Storage.DeleteImagesFromDetailSections(string(heritage.HeritageDetailSections)); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete images from heritage detail sections: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete heritage")
 	}
 
 	return c.JSON(fiber.Map{
@@ -114,37 +121,27 @@ func DeleteHeritage(c *fiber.Ctx) error {
 
 // UpdateHeritagePageContent updates the heritage page content (singleton)
 func UpdateHeritagePageContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.HeritagePageContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req HeritagePageContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.HeritagePageContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create heritage page content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create heritage page content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update heritage page content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "heritage-page-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update heritage page content"))
 	}
 
 	return c.JSON(content)
@@ -158,7 +155,8 @@ func UpdateHeritagePageContent(c *fiber.Ctx) error {
 func GetHeritage(c *fiber.Ctx) error {
 	var heritage []models.Heritage
 	query := config.DB.Model(&models.Heritage{}).
-		Select("id, title, title_id, short_description, short_description_id, image_url, thumbnail_url, sort_order")
+		Scopes(publicationScope(c)).
+		Select("id, title, title_id, short_description, short_description_id, image_url, thumbnail_url, sort_order, status, publish_at, unpublish_at, slug, slug_id")
 
 	// Apply limit and offset for pagination
 	limit := 12
@@ -178,12 +176,15 @@ func GetHeritage(c *fiber.Ctx) error {
 	query = query.Limit(limit).Offset(offset)
 
 	if err := query.Order("sort_order ASC, created_at ASC").Find(&heritage).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch heritage data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch heritage data")
+	}
+
+	// Placeholders, focal points and variants of the images
+	imageURLs := make([]string, len(heritage))
+	for i, h := range heritage {
+		imageURLs[i] = h.ImageURL
 	}
+	imageMeta := models.LookupImageMeta(config.DB, imageURLs...)
 
 	// Convert to summary format
 	heritageSummaries := make([]models.HeritageSummary, len(heritage))
@@ -197,12 +198,15 @@ func GetHeritage(c *fiber.Ctx) error {
 			ImageURL:           h.ImageURL,
 			ThumbnailURL:       h.ThumbnailURL,
 			SortOrder:          h.SortOrder,
+			Publication:        h.Publication,
+			Slugs:              h.Slugs,
+			ImageMeta:          imageMeta[h.ImageURL],
 		}
 	}
 
 	// Get total count
 	var total int64
-	config.DB.Model(&models.Heritage{}).Count(&total)
+	config.DB.Model(&models.Heritage{}).Scopes(publicationScope(c)).Count(&total)
 
 	// Calculate pagination
 	totalPages := 0
@@ -232,19 +236,20 @@ func GetHeritage(c *fiber.Ctx) error {
 	})
 }
 
-// GetHeritageByID returns a specific heritage by ID
+// GetHeritageByID returns a specific heritage by ID or slug
 func GetHeritageByID(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, redirectTo := resolveDetailParam(c, "heritage")
+	if redirectTo != "" {
+		return c.Redirect(redirectTo, fiber.StatusMovedPermanently)
+	}
 
 	var heritage models.Heritage
-	if err := config.DB.Where("id = ?", id).First(&heritage).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Heritage not found",
-			"code":    "NOT_FOUND",
-		})
+	if err := config.DB.Where("id = ?", id).First(&heritage).Error; err != nil || !canViewContent(c, "heritage", heritage.ID, heritage.Publication) {
+		return apierror.NotFound("Heritage not found")
 	}
 
+	heritage.ImageMeta = models.LookupImageMeta(config.DB, heritage.ImageURL)[heritage.ImageURL]
+	setETag(c, heritage.Version)
 	return c.JSON(fiber.Map{
 		"data": heritage,
 	})
@@ -259,6 +264,7 @@ func GetHeritagePageContent(c *fiber.Ctx) error {
 			"data": models.HeritagePageContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/jobs"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// UpdateMediaAssetRequest represents the editable metadata of a media asset. Fields left
+// out of the body keep their values; "crop": null removes the crop rectangle.
+type UpdateMediaAssetRequest struct {
+	AltText   *string          `json:"alt_text"`
+	AltTextID *string          `json:"alt_text_id"`
+	FocalX    *float64         `json:"focal_x" validate:"omitempty,gte=0,lte=100"`
+	FocalY    *float64         `json:"focal_y" validate:"omitempty,gte=0,lte=100"`
+	Crop      *utils.ImageCrop `json:"crop"`
+}
+
+// mediaAssetListItem is a media asset with the number of places the current content uses it
+type mediaAssetListItem struct {
+	models.MediaAsset
+	UsageCount int `json:"usage_count"`
+}
+
+// =============================================================================
+// MEDIA HELPERS
+// =============================================================================
+
+// deleteReplacedImages removes images from R2 that an update replaced. before is the stored
+// snapshot of the row; images still used by other content (including trashed content) or by a
+// revision that can be restored are kept, and left to the media GC once that content is gone.
+// Must only be called after the update has been committed.
+func deleteReplacedImages(before []byte, after interface{}) {
+	if utils.Storage == nil || before == nil {
+		return
+	}
+
+	replaced := utils.ReplacedImageRefs(json.RawMessage(before), after)
+	if len(replaced) == 0 {
+		return
+	}
+
+	urls := make([]string, len(replaced))
+	for i, ref := range replaced {
+		urls[i] = ref.URL
+	}
+	usageIndex, err := models.FindMediaUsages(config.DB, urls)
+	if err != nil {
+		fmt.Printf("Warning: Failed to check image usage, keeping replaced images: %v\n", err)
+		return
+	}
+
+	var deleted []string
+	for _, ref := range replaced {
+		if len(usageIndex[ref.URL]) > 0 || !utils.Storage.IsR2URL(ref.URL) {
+			continue
+		}
+
+		deleteImage := utils.Storage.DeleteImageWithThumbnailIfR2
+		if ref.Thumbnail {
+			deleteImage = utils.Storage.DeleteImageIfR2
+		}
+		if err := deleteImage(ref.URL); err != nil {
+			fmt.Printf("Warning: Failed to delete replaced image from R2: %v\n", err)
+			continue
+		}
+		deleted = append(deleted, ref.URL)
+	}
+
+	if len(deleted) > 0 {
+		if err := config.DB.Unscoped().Where("url IN ?", deleted).Delete(&models.MediaAsset{}).Error; err != nil {
+			fmt.Printf("Warning: Failed to remove media records of replaced images: %v\n", err)
+		}
+	}
+}
+
+// regenerateCroppedVariants generates the cropped variants of an asset for a new focus and
+// returns its variant list with them replaced
+func regenerateCroppedVariants(asset models.MediaAsset, focus utils.ImageFocus) (datatypes.JSON, error) {
+	if err := requireStorage(); err != nil {
+		return nil, err
+	}
+
+	cropped, err := utils.Storage.RegenerateCroppedVariants(asset.Key, focus)
+	if err != nil {
+		return nil, apierror.Internal("Failed to generate cropped variants: " + err.Error())
+	}
+
+	variants := map[string]utils.ImageVariant{}
+	if len(asset.Variants) > 0 {
+		if err := json.Unmarshal(asset.Variants, &variants); err != nil {
+			return nil, apierror.Internal("Failed to read media variants")
+		}
+	}
+	for name, variant := range variants {
+		if variant.Crop {
+			delete(variants, name)
+		}
+	}
+	for name, variant := range cropped {
+		variants[name] = variant
+	}
+
+	data, _ := json.Marshal(variants)
+	return datatypes.JSON(data), nil
+}
+
+// =============================================================================
+// MEDIA LIBRARY - ADMIN
+// =============================================================================
+
+// GetMedia lists uploaded media assets, newest first.
+// Filters: ?q= (filename or alt text), ?folder=, ?type= (content type prefix, e.g. image/),
+// ?media_type= (image, video or panorama), ?used=true|false; paginated with ?limit= and ?offset=.
+func GetMedia(c *fiber.Ctx) error {
+	filter := func(query *gorm.DB) *gorm.DB {
+		if q := strings.TrimSpace(c.Query("q")); q != "" {
+			like := "%" + q + "%"
+			query = query.Where("filename ILIKE ? OR alt_text ILIKE ? OR alt_text_id ILIKE ?", like, like, like)
+		}
+		if folder := c.Query("folder"); folder != "" {
+			query = query.Where("folder = ?", folder)
+		}
+		if contentType := c.Query("type"); contentType != "" {
+			query = query.Where("content_type LIKE ?", contentType+"%")
+		}
+		if mediaType := c.Query("media_type"); mediaType != "" {
+			query = query.Where("media_type = ?", mediaType)
+		}
+		if used := c.Query("used"); used == "true" {
+			query = query.Where(models.MediaUsedCondition)
+		} else if used != "" {
+			query = query.Where("NOT " + models.MediaUsedCondition)
+		}
+		return query
+	}
+
+	// Apply limit and offset for pagination
+	limit := 24
+	if l := c.Query("limit"); l != "" {
+		if limitInt, err := strconv.Atoi(l); err == nil && limitInt > 0 {
+			limit = limitInt
+		}
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if offsetInt, err := strconv.Atoi(o); err == nil && offsetInt >= 0 {
+			offset = offsetInt
+		}
+	}
+
+	var assets []models.MediaAsset
+	if err := filter(config.DB.Model(&models.MediaAsset{})).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&assets).Error; err != nil {
+		return apierror.Internal("Failed to fetch media")
+	}
+
+	var total int64
+	filter(config.DB.Model(&models.MediaAsset{})).Count(&total)
+
+	var urls []string
+	for _, asset := range assets {
+		urls = append(urls, asset.URL)
+		if asset.ThumbnailURL != "" {
+			urls = append(urls, asset.ThumbnailURL)
+		}
+	}
+	usageIndex, err := models.FindMediaUsages(config.DB, urls)
+	if err != nil {
+		return apierror.Internal("Failed to load media usage")
+	}
+
+	items := make([]mediaAssetListItem, len(assets))
+	for i, asset := range assets {
+		items[i] = mediaAssetListItem{MediaAsset: asset}
+		for _, usage := range usageIndex.Usages(asset) {
+			if !usage.Revision {
+				items[i].UsageCount++
+			}
+		}
+	}
+
+	// Folders with asset counts, for browsing
+	var folders []struct {
+		Folder string `json:"folder"`
+		Count  int64  `json:"count"`
+	}
+	config.DB.Model(&models.MediaAsset{}).
+		Select("folder, COUNT(*) AS count").
+		Group("folder").
+		Order("folder ASC").
+		Scan(&folders)
+
+	// Calculate pagination
+	totalPages := int(total) / limit
+	if int(total)%limit != 0 {
+		totalPages++
+	}
+	currentPage := (offset / limit) + 1
+
+	return c.JSON(fiber.Map{
+		"data": items,
+		"meta": fiber.Map{
+			"total":   total,
+			"folders": folders,
+			"pagination": fiber.Map{
+				"current_page": currentPage,
+				"per_page":     limit,
+				"total_pages":  totalPages,
+				"has_next":     currentPage < totalPages,
+				"has_previous": currentPage > 1,
+			},
+		},
+	})
+}
+
+// GetMediaAsset returns a media asset and every place in the content that uses it
+func GetMediaAsset(c *fiber.Ctx) error {
+	var asset models.MediaAsset
+	if err := config.DB.Where("id = ?", c.Params("id")).First(&asset).Error; err != nil {
+		return apierror.NotFound("Media asset not found")
+	}
+
+	usageIndex, err := models.FindMediaUsages(config.DB, []string{asset.URL, asset.ThumbnailURL})
+	if err != nil {
+		return apierror.Internal("Failed to load media usage")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"asset":  asset,
+			"usages": usageIndex.Usages(asset),
+		},
+	})
+}
+
+// UpdateMediaAsset updates the alt texts, focal point and crop rectangle of a media asset.
+// When the focal point or crop changes, the cropped variants are generated again. Videos have
+// neither, so setting them is a bad request.
+func UpdateMediaAsset(c *fiber.Ctx) error {
+	var asset models.MediaAsset
+	if err := config.DB.Where("id = ?", c.Params("id")).First(&asset).Error; err != nil {
+		return apierror.NotFound("Media asset not found")
+	}
+
+	var req UpdateMediaAssetRequest
+	if err := apierror.Bind(c, &req); err != nil {
+		return err
+	}
+	if req.Crop != nil && !req.Crop.Fits() {
+		return apierror.Validation([]apierror.FieldError{{Field: "crop", Message: "must lie within the image"}})
+	}
+	// A null crop removes it, a missing one keeps it
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return apierror.BadRequest("Invalid request body")
+	}
+	_, cropGiven := body["crop"]
+	if asset.MediaType == utils.MediaTypeVideo && (req.FocalX != nil || req.FocalY != nil || cropGiven) {
+		return apierror.BadRequest("Videos have no focal point or crop")
+	}
+
+	updates := map[string]interface{}{
+		"version": gorm.Expr("version + 1"),
+	}
+	if req.AltText != nil {
+		updates["alt_text"] = *req.AltText
+	}
+	if req.AltTextID != nil {
+		updates["alt_text_id"] = *req.AltTextID
+	}
+
+	focus := asset.Focus()
+	if req.FocalX != nil {
+		focus.FocalX = *req.FocalX
+		updates["focal_x"] = focus.FocalX
+	}
+	if req.FocalY != nil {
+		focus.FocalY = *req.FocalY
+		updates["focal_y"] = focus.FocalY
+	}
+	if cropGiven {
+		focus.Crop = req.Crop
+		crop := utils.ImageCrop{}
+		if req.Crop != nil {
+			crop = *req.Crop
+		}
+		updates["crop_x"] = crop.X
+		updates["crop_y"] = crop.Y
+		updates["crop_width"] = crop.Width
+		updates["crop_height"] = crop.Height
+	}
+
+	// SVGs scale by themselves and have no variants to crop
+	hasVariants := strings.HasPrefix(asset.ContentType, "image/") && asset.ContentType != "image/svg+xml" && asset.Width > 0
+	if hasVariants && !reflect.DeepEqual(focus, asset.Focus()) {
+		variants, err := regenerateCroppedVariants(asset, focus)
+		if err != nil {
+			return err
+		}
+		updates["variants"] = variants
+	}
+
+	if err := config.DB.Model(&asset).Updates(updates).Error; err != nil {
+		return apierror.Internal("Failed to update media asset")
+	}
+
+	if err := config.DB.First(&asset, asset.ID).Error; err != nil {
+		return apierror.Internal("Failed to fetch media asset")
+	}
+	return c.JSON(asset)
+}
+
+// FindDuplicateMedia reports files that are stored more than once in the bucket, with the
+// places each copy is used, so editors can point content at one copy and let the GC remove the rest
+func FindDuplicateMedia(c *fiber.Ctx) error {
+	report, err := jobs.FindDuplicateMedia()
+	if err != nil {
+		return apierror.Internal("Failed to find duplicate media: " + err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// CollectOrphanedMedia finds R2 objects that no content references. Runs as a dry run
+// unless ?dry_run=false is given, in which case the orphans are deleted.
+func CollectOrphanedMedia(c *fiber.Ctx) error {
+	dryRun := c.Query("dry_run") != "false"
+
+	report, err := jobs.CollectOrphanedMedia(dryRun)
+	if err != nil {
+		return apierror.Internal("Failed to collect orphaned media: " + err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    report,
+	})
+}
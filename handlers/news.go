@@ -1,16 +1,62 @@
 package handlers
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
-	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
 )
 
+// NewsArticleRequest is the body of POST /news and PUT /news/:id
+type NewsArticleRequest struct {
+	models.Publication
+	models.Slugs
+	Title         string         `json:"title" validate:"required"`
+	TitleID       string         `json:"title_id" validate:"required"`
+	Excerpt       string         `json:"excerpt"`
+	ExcerptID     string         `json:"excerpt_id"`
+	Content       string         `json:"content" validate:"required"`
+	ContentID     string         `json:"content_id"`
+	AuthorID      uint           `json:"author_id" validate:"required"`
+	DatePublished time.Time      `json:"date_published"`
+	CategoryID    uint           `json:"category_id" validate:"required"`
+	ImageURL      string         `json:"image_url" validate:"omitempty,link"`
+	Tags          datatypes.JSON `json:"tags"`
+	ReadTime      int            `json:"read_time" validate:"gte=0"`
+	IsHeadline    bool           `json:"is_headline"`
+}
+
+// NewsPageContentRequest is the body of PUT /news/content
+type NewsPageContentRequest struct {
+	HeroImageURL            string `json:"hero_image_url" validate:"omitempty,link"`
+	HeroImageThumbnailURL   string `json:"hero_image_thumbnail_url" validate:"omitempty,link"`
+	Title                   string `json:"title"`
+	TitleID                 string `json:"title_id"`
+	Subtitle                string `json:"subtitle"`
+	SubtitleID              string `json:"subtitle_id"`
+	HighlightSectionTitle   string `json:"highlight_section_title"`
+	HighlightSectionTitleID string `json:"highlight_section_title_id"`
+}
+
+// NewsCategoryRequest is the body of POST /news/categories and PUT /news/categories/:id
+type NewsCategoryRequest struct {
+	Name          string `json:"name" validate:"required"`
+	NameID        string `json:"name_id" validate:"required"`
+	Description   string `json:"description"`
+	DescriptionID string `json:"description_id"`
+}
+
+// NewsAuthorRequest is the body of POST /news/authors and PUT /news/authors/:id
+type NewsAuthorRequest struct {
+	Name   string `json:"name" validate:"required"`
+	Avatar string `json:"avatar" validate:"omitempty,link"`
+}
+
 // =============================================================================
 // NEWS MANAGEMENT - ADMIN
 // =============================================================================
@@ -18,20 +64,17 @@ import (
 // CreateNews creates a new news article
 func CreateNews(c *fiber.Ctx) error {
 	var news models.NewsArticle
-	if err := c.BodyParser(&news); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req NewsArticleRequest
+	if err := bindRequest(c, &req, &news); err != nil {
+		return err
 	}
 
-	if err := config.DB.Create(&news).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create news article",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := news.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := models.RetryOnSlugConflict(func() error { return config.DB.Create(&news).Error }); err != nil {
+		return apierror.Internal("Failed to create news article")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(news)
@@ -43,27 +86,22 @@ func UpdateNews(c *fiber.Ctx) error {
 
 	var news models.NewsArticle
 	if err := config.DB.Where("id = ?", id).First(&news).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "News article not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("News article not found")
 	}
 
-	if err := c.BodyParser(&news); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(news)
+
+	var req NewsArticleRequest
+	if err := bindRequest(c, &req, &news); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&news).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update news article",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := news.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "news", news.ID, before, &news); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update news article"))
 	}
 
 	return c.JSON(news)
@@ -73,32 +111,15 @@ func UpdateNews(c *fiber.Ctx) error {
 func DeleteNews(c *fiber.Ctx) error {
 	id := c.Params("id")
 
-	// First, get the news article to retrieve URLs for R2 deletion
+	// Make sure the news article exists
 	var news models.NewsArticle
 	if err := config.DB.Where("id = ?", id).First(&news).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "News article not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("News article not found")
 	}
 
-	// Delete from database
+	// Soft delete only; images stay in R2 until the item is purged from the trash
 	if err := config.DB.Delete(&models.NewsArticle{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete news article",
-			"code":    "INTERNAL_ERROR",
-		})
-	}
-
-	// Delete images from R2 if they are from our R2 bucket
-	if utils.Storage != nil {
-		// Delete main image
-		if err := utils.Storage.DeleteImageIfR2(news.ImageURL); err != nil {
-			// Log error but don't fail the request since DB deletion succeeded
-			fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
-		}
+		return apierror.Internal("Failed to delete news article")
 	}
 
 	return c.JSON(fiber.Map{
@@ -109,37 +130,27 @@ func DeleteNews(c *fiber.Ctx) error {
 
 // UpdateNewsPageContent updates the news page content (singleton)
 func UpdateNewsPageContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.NewsPageContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req NewsPageContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.NewsPageContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create news page content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create news page content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update news page content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "news-page-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update news page content"))
 	}
 
 	return c.JSON(content)
@@ -152,20 +163,13 @@ func UpdateNewsPageContent(c *fiber.Ctx) error {
 // CreateNewsCategory creates a new news category
 func CreateNewsCategory(c *fiber.Ctx) error {
 	var category models.NewsCategory
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req NewsCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
 	if err := config.DB.Create(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create news category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create news category")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(category)
@@ -177,27 +181,18 @@ func UpdateNewsCategory(c *fiber.Ctx) error {
 
 	var category models.NewsCategory
 	if err := config.DB.Where("id = ?", id).First(&category).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "News category not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("News category not found")
 	}
 
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(category)
+
+	var req NewsCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update news category",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "news-categories", category.ID, before, &category); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update news category"))
 	}
 
 	return c.JSON(category)
@@ -208,11 +203,7 @@ func DeleteNewsCategory(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.NewsCategory{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete news category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete news category")
 	}
 
 	return c.JSON(fiber.Map{
@@ -228,20 +219,13 @@ func DeleteNewsCategory(c *fiber.Ctx) error {
 // CreateNewsAuthor creates a new news author
 func CreateNewsAuthor(c *fiber.Ctx) error {
 	var author models.NewsAuthor
-	if err := c.BodyParser(&author); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req NewsAuthorRequest
+	if err := bindRequest(c, &req, &author); err != nil {
+		return err
 	}
 
 	if err := config.DB.Create(&author).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create news author",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create news author")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(author)
@@ -253,27 +237,18 @@ func UpdateNewsAuthor(c *fiber.Ctx) error {
 
 	var author models.NewsAuthor
 	if err := config.DB.Where("id = ?", id).First(&author).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "News author not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("News author not found")
 	}
 
-	if err := c.BodyParser(&author); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(author)
+
+	var req NewsAuthorRequest
+	if err := bindRequest(c, &req, &author); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&author).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update news author",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "news-authors", author.ID, before, &author); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update news author"))
 	}
 
 	return c.JSON(author)
@@ -284,11 +259,7 @@ func DeleteNewsAuthor(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.NewsAuthor{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete news author",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete news author")
 	}
 
 	return c.JSON(fiber.Map{
@@ -305,7 +276,8 @@ func DeleteNewsAuthor(c *fiber.Ctx) error {
 func GetNews(c *fiber.Ctx) error {
 	var news []models.NewsArticle
 	query := config.DB.Model(&models.NewsArticle{}).
-		Select("id, title, title_id, excerpt, excerpt_id, image_url, date_published, author_id, category_id, tags, read_time, is_headline").
+		Scopes(publicationScope(c)).
+		Select("id, title, title_id, excerpt, excerpt_id, image_url, date_published, author_id, category_id, tags, read_time, is_headline, status, publish_at, unpublish_at, slug, slug_id").
 		Preload("NewsAuthor").
 		Preload("NewsCategory")
 
@@ -359,11 +331,7 @@ func GetNews(c *fiber.Ctx) error {
 	query = query.Limit(limit).Offset(offset)
 
 	if err := query.Order("date_published DESC, created_at DESC").Find(&news).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch news data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch news data")
 	}
 
 	// Convert to summary format
@@ -384,12 +352,14 @@ func GetNews(c *fiber.Ctx) error {
 			Tags:          article.Tags,
 			ReadTime:      article.ReadTime,
 			IsHeadline:    article.IsHeadline,
+			Publication:   article.Publication,
+			Slugs:         article.Slugs,
 		}
 	}
 
 	// Get total count (apply same filters)
 	var total int64
-	countQuery := config.DB.Model(&models.NewsArticle{})
+	countQuery := config.DB.Model(&models.NewsArticle{}).Scopes(publicationScope(c))
 	if isHeadline := c.Query("headline"); isHeadline == "true" {
 		countQuery = countQuery.Where("is_headline = ?", true)
 	}
@@ -448,19 +418,19 @@ func GetNews(c *fiber.Ctx) error {
 	})
 }
 
-// GetNewsByID returns a specific news article by ID
+// GetNewsByID returns a specific news article by ID or slug
 func GetNewsByID(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, redirectTo := resolveDetailParam(c, "news")
+	if redirectTo != "" {
+		return c.Redirect(redirectTo, fiber.StatusMovedPermanently)
+	}
 
 	var news models.NewsArticle
-	if err := config.DB.Preload("NewsAuthor").Preload("NewsCategory").Where("id = ?", id).First(&news).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "News article not found",
-			"code":    "NOT_FOUND",
-		})
+	if err := config.DB.Preload("NewsAuthor").Preload("NewsCategory").Where("id = ?", id).First(&news).Error; err != nil || !canViewContent(c, "news", news.ID, news.Publication) {
+		return apierror.NotFound("News article not found")
 	}
 
+	setETag(c, news.Version)
 	return c.JSON(fiber.Map{
 		"data": news,
 	})
@@ -471,7 +441,7 @@ func GetNewsCategories(c *fiber.Ctx) error {
 	categories := getNewsCategoriesFromTable()
 
 	var totalNews int64
-	config.DB.Model(&models.NewsArticle{}).Count(&totalNews)
+	config.DB.Model(&models.NewsArticle{}).Scopes(models.Published).Count(&totalNews)
 
 	return c.JSON(fiber.Map{
 		"data": categories,
@@ -487,7 +457,7 @@ func GetNewsAuthors(c *fiber.Ctx) error {
 	authors := getNewsAuthorsFromTable()
 
 	var totalNews int64
-	config.DB.Model(&models.NewsArticle{}).Count(&totalNews)
+	config.DB.Model(&models.NewsArticle{}).Scopes(models.Published).Count(&totalNews)
 
 	return c.JSON(fiber.Map{
 		"data": authors,
@@ -504,13 +474,10 @@ func GetNewsAuthorByID(c *fiber.Ctx) error {
 
 	var author models.NewsAuthor
 	if err := config.DB.Where("id = ?", id).First(&author).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "News author not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("News author not found")
 	}
 
+	setETag(c, author.Version)
 	return c.JSON(fiber.Map{
 		"data": author,
 	})
@@ -525,6 +492,7 @@ func GetNewsPageContent(c *fiber.Ctx) error {
 			"data": models.NewsPageContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -540,7 +508,7 @@ func getNewsCategoriesFromTable() []fiber.Map {
 	categories := make([]fiber.Map, 0, len(catRows))
 	for _, cat := range catRows {
 		var count int64
-		config.DB.Model(&models.NewsArticle{}).Where("category_id = ?", cat.ID).Count(&count)
+		config.DB.Model(&models.NewsArticle{}).Scopes(models.Published).Where("category_id = ?", cat.ID).Count(&count)
 
 		categories = append(categories, fiber.Map{
 			"id":             cat.ID,
@@ -564,7 +532,7 @@ func getNewsAuthorsFromTable() []fiber.Map {
 	authors := make([]fiber.Map, 0, len(authorRows))
 	for _, author := range authorRows {
 		var count int64
-		config.DB.Model(&models.NewsArticle{}).Where("author_id = ?", author.ID).Count(&count)
+		config.DB.Model(&models.NewsArticle{}).Scopes(models.Published).Where("author_id = ?", author.ID).Count(&count)
 
 		authors = append(authors, fiber.Map{
 			"id":     author.ID,
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ReorderRequest is the body of the bulk reorder endpoints: IDs in their new display order
+type ReorderRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1"`
+}
+
+// =============================================================================
+// ORDER MANAGEMENT - ADMIN
+// =============================================================================
+
+// ReorderCarousel sets carousel_order for carousel slides
+func ReorderCarousel(c *fiber.Ctx) error {
+	return reorderCollection(c, "carousel", "carousel_order")
+}
+
+// ReorderSellingPoints sets selling_point_order for selling points
+func ReorderSellingPoints(c *fiber.Ctx) error {
+	return reorderCollection(c, "selling-points", "selling_point_order")
+}
+
+// ReorderAttractions sets sort_order for attractions
+func ReorderAttractions(c *fiber.Ctx) error {
+	return reorderCollection(c, "attractions", "sort_order")
+}
+
+// ReorderDestinations sets sort_order for destinations
+func ReorderDestinations(c *fiber.Ctx) error {
+	return reorderCollection(c, "destinations", "sort_order")
+}
+
+// ReorderFacilities sets sort_order for facilities
+func ReorderFacilities(c *fiber.Ctx) error {
+	return reorderCollection(c, "facilities", "sort_order")
+}
+
+// ReorderHeritage sets sort_order for heritage items
+func ReorderHeritage(c *fiber.Ctx) error {
+	return reorderCollection(c, "heritage", "sort_order")
+}
+
+// reorderCollection assigns positions 1..n to the given IDs in a single transaction.
+// Items missing from the list keep their relative order and are placed after the listed ones.
+// Items whose position changes get a new version and a revision, as any other update.
+func reorderCollection(c *fiber.Ctx, contentType, column string) error {
+	var req ReorderRequest
+	if err := apierror.Bind(c, &req); err != nil {
+		return err
+	}
+
+	seen := make(map[uint]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			return apierror.BadRequest(fmt.Sprintf("Duplicate ID %d in ids", id))
+		}
+		seen[id] = true
+	}
+
+	ct, _ := models.LookupContentType(contentType)
+
+	var positions []struct {
+		ID       uint
+		Position int
+	}
+	if err := config.DB.Model(ct.New()).Select("id, " + column + " AS position").Order(column + " ASC, created_at ASC").Scan(&positions).Error; err != nil {
+		return apierror.Internal("Failed to fetch " + contentType)
+	}
+
+	existingIDs := make([]uint, 0, len(positions))
+	existing := make(map[uint]int, len(positions))
+	for _, row := range positions {
+		existingIDs = append(existingIDs, row.ID)
+		existing[row.ID] = row.Position
+	}
+
+	var unknown []uint
+	for _, id := range req.IDs {
+		if _, ok := existing[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return apierror.BadRequest(fmt.Sprintf("IDs do not belong to %s: %v", contentType, unknown)).With("unknown_ids", unknown)
+	}
+
+	// Listed IDs first, then the rest in their current order
+	order := append([]uint{}, req.IDs...)
+	for _, id := range existingIDs {
+		if !seen[id] {
+			order = append(order, id)
+		}
+	}
+
+	if err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range order {
+			if existing[id] == i+1 {
+				continue
+			}
+
+			row := ct.New()
+			if err := tx.Where("id = ?", id).First(row).Error; err != nil {
+				return err
+			}
+			before := revisionSnapshot(row)
+
+			if err := tx.Session(&gorm.Session{SkipHooks: true}).Model(ct.New()).Where("id = ?", id).Updates(map[string]interface{}{
+				column:    i + 1,
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+
+			row = ct.New()
+			if err := tx.Where("id = ?", id).First(row).Error; err != nil {
+				return err
+			}
+			if err := recordRevision(tx, c, contentType, id, before, models.RevisionActionUpdate, row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return apierror.Internal("Failed to update order")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": ct.Name + " order updated successfully",
+		"data":    order,
+	})
+}
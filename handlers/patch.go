@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+)
+
+// patchField is a JSON field of a model that PATCH may change
+type patchField struct {
+	Index []int
+	Type  reflect.Type
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var jsonType = reflect.TypeOf(datatypes.JSON{})
+
+// =============================================================================
+// PATCH MANAGEMENT - ADMIN
+// =============================================================================
+
+// PatchContent returns a handler that partially updates an item of the given content type.
+// Only the fields present in the body are changed.
+func PatchContent(contentType string) fiber.Handler {
+	ct, _ := models.LookupContentType(contentType)
+
+	return func(c *fiber.Ctx) error {
+		model := ct.New()
+		if err := config.DB.Where("id = ?", c.Params("id")).First(model).Error; err != nil {
+			return apierror.NotFound(ct.Name + " not found")
+		}
+
+		before := revisionSnapshot(model)
+
+		fieldErrors, err := patchModel(c, ct, model)
+		if err != nil {
+			return apierror.BadRequest("Invalid request body")
+		}
+		if len(fieldErrors) > 0 {
+			return apierror.Validation(fieldErrors)
+		}
+
+		if err := saveWithRevision(c, ct.Key, modelID(model), before, model); err != nil {
+			return apierror.Or(err, apierror.Internal("Failed to update "+strings.ToLower(ct.Name)))
+		}
+
+		return c.JSON(model)
+	}
+}
+
+// PatchSingleton returns a handler that partially updates a page content singleton,
+// creating the row if it does not exist yet
+func PatchSingleton(contentType string) fiber.Handler {
+	ct, _ := models.LookupContentType(contentType)
+
+	return func(c *fiber.Ctx) error {
+		model := ct.New()
+		exists := config.DB.First(model).Error == nil
+		before := revisionSnapshot(model)
+
+		fieldErrors, err := patchModel(c, ct, model)
+		if err != nil {
+			return apierror.BadRequest("Invalid request body")
+		}
+		if len(fieldErrors) > 0 {
+			return apierror.Validation(fieldErrors)
+		}
+
+		if !exists {
+			if err := config.DB.Create(model).Error; err != nil {
+				return apierror.Internal("Failed to create " + strings.ToLower(ct.Name))
+			}
+			return c.Status(fiber.StatusCreated).JSON(model)
+		}
+
+		if err := saveWithRevision(c, ct.Key, modelID(model), before, model); err != nil {
+			return apierror.Or(err, apierror.Internal("Failed to update "+strings.ToLower(ct.Name)))
+		}
+
+		return c.JSON(model)
+	}
+}
+
+// patchModel applies the whitelisted fields of the request body to model and validates them.
+// The error is set when the body is not a JSON object.
+func patchModel(c *fiber.Ctx, ct models.ContentType, model interface{}) ([]apierror.FieldError, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return nil, err
+	}
+
+	// Only whitelisted fields may be changed; values are checked by the request struct's validate tags
+	// and, for ID fields, models.ContentReferences
+	references := models.ContentReferences(ct.Key)
+	columns := map[string]patchField{}
+	collectPatchableFields(reflect.TypeOf(model).Elem(), nil, columns)
+	fields := map[string]patchField{}
+	for _, name := range models.PatchableFields(ct.Key) {
+		if field, ok := columns[name]; ok {
+			fields[name] = field
+		}
+	}
+
+	// Walk the fields in a stable order so errors are reported consistently
+	names := make([]string, 0, len(body))
+	for name := range body {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	value := reflect.ValueOf(model).Elem()
+	var fieldErrors []apierror.FieldError
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: name, Message: "field cannot be updated"})
+			continue
+		}
+
+		target := reflect.New(field.Type)
+		if err := json.Unmarshal(body[name], target.Interface()); err != nil {
+			fieldErrors = append(fieldErrors, apierror.FieldError{Field: name, Message: fmt.Sprintf("must be of type %s", describeType(field.Type))})
+			continue
+		}
+
+		if reference := references[name]; reference != "" {
+			if message := validateReference(target.Elem(), reference); message != "" {
+				fieldErrors = append(fieldErrors, apierror.FieldError{Field: name, Message: message})
+				continue
+			}
+		}
+
+		value.FieldByIndex(field.Index).Set(target.Elem())
+	}
+
+	// Validate the patched model, but only report the fields the request touched so
+	// rows that predate a rule can still be patched
+	for _, fieldError := range validateModel(ct, model) {
+		path := strings.Split(fieldError.Field, ".")
+		if _, ok := body[path[len(path)-1]]; ok {
+			fieldError.Field = path[len(path)-1]
+			fieldErrors = append(fieldErrors, fieldError)
+		}
+	}
+
+	if len(fieldErrors) == 0 {
+		if publication := value.FieldByName("Publication"); publication.IsValid() {
+			if err := publication.Addr().Interface().(*models.Publication).Normalize(time.Now()); err != nil {
+				fieldErrors = append(fieldErrors, apierror.FieldError{Field: "status", Message: err.Error()})
+			}
+		}
+	}
+
+	return fieldErrors, nil
+}
+
+// validateReference checks that an ID field points at an existing row and returns an error message, or "" when valid
+func validateReference(value reflect.Value, reference string) string {
+	referenced, _ := models.LookupContentType(reference)
+	if value.Uint() == 0 || config.DB.Where("id = ?", value.Uint()).First(referenced.New()).Error != nil {
+		return fmt.Sprintf("must reference an existing %s", strings.ToLower(referenced.Name))
+	}
+	return ""
+}
+
+// collectPatchableFields maps JSON names to struct fields, descending into embedded structs.
+// IDs, timestamps, versions, hidden columns and associations are never patchable.
+func collectPatchableFields(t reflect.Type, parent []int, fields map[string]patchField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectPatchableFields(field.Type, index, fields)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "id" || name == "created_at" || name == "updated_at" || name == "version" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			continue
+		}
+		if field.Tag.Get("gorm") == "-" {
+			continue // Computed for responses, not stored
+		}
+
+		fields[name] = patchField{Index: index, Type: field.Type}
+	}
+}
+
+// describeType names a field type for validation messages
+func describeType(t reflect.Type) string {
+	switch {
+	case t == jsonType:
+		return "JSON"
+	case t == timeType, t.Kind() == reflect.Ptr && t.Elem() == timeType:
+		return "RFC 3339 timestamp"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return t.Kind().String()
+}
+
+// modelID reads the primary key of a model embedding BaseModel
+func modelID(model interface{}) uint {
+	return uint(reflect.ValueOf(model).Elem().FieldByName("ID").Uint())
+}
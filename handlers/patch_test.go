@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"yaro-wora-be/models"
+)
+
+func TestPatchableFieldsExist(t *testing.T) {
+	for _, ct := range models.ContentTypes() {
+		t.Run(ct.Key, func(t *testing.T) {
+			fields := map[string]patchField{}
+			collectPatchableFields(reflect.TypeOf(ct.New()).Elem(), nil, fields)
+
+			whitelist := models.PatchableFields(ct.Key)
+			if len(whitelist) == 0 {
+				t.Fatalf("no patchable fields registered for %s", ct.Key)
+			}
+			for _, name := range whitelist {
+				if _, ok := fields[name]; !ok {
+					t.Errorf("patchable field %q is not a stored field of %s", name, ct.Key)
+				}
+			}
+		})
+	}
+}
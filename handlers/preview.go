@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// previewPaths maps previewable content types to their public detail route
+var previewPaths = map[string]string{
+	"destinations": "/destinations/%d",
+	"facilities":   "/facilities/%d",
+	"gallery":      "/gallery/%d",
+	"heritage":     "/heritage/%d",
+	"news":         "/news/%d",
+	"regulations":  "/regulations/%d",
+}
+
+// CreatePreviewLinkRequest represents the preview link request body
+type CreatePreviewLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" validate:"gte=0,lte=720"` // 0 uses PREVIEW_TOKEN_TTL_HOURS; at most 30 days
+}
+
+// =============================================================================
+// PREVIEW LINKS - ADMIN
+// =============================================================================
+
+// CreatePreviewLink generates a signed, expiring preview link for an unpublished item
+func CreatePreviewLink(c *fiber.Ctx) error {
+	contentType := c.Params("type")
+	pathFormat, ok := previewPaths[contentType]
+	if !ok {
+		return apierror.BadRequest("Preview is not supported for this content type")
+	}
+
+	var req CreatePreviewLinkRequest
+	if len(c.Body()) > 0 {
+		if err := apierror.Bind(c, &req); err != nil {
+			return err
+		}
+	}
+
+	contentID, err := c.ParamsInt("id")
+	if err != nil || contentID <= 0 {
+		return apierror.BadRequest("Invalid content ID")
+	}
+
+	ct, _ := models.LookupContentType(contentType)
+	item := ct.New()
+	if err := config.DB.Where("id = ?", contentID).First(item).Error; err != nil {
+		return apierror.NotFound("Content not found")
+	}
+
+	ttlHours := config.AppConfig.PreviewTokenTTLHours
+	if req.ExpiresInHours > 0 {
+		ttlHours = req.ExpiresInHours
+	}
+
+	token, expiresAt, err := utils.GeneratePreviewToken(contentType, uint(contentID), c.Locals("username").(string), time.Duration(ttlHours)*time.Hour)
+	if err != nil {
+		return apierror.Internal("Failed to generate preview token")
+	}
+
+	path := fmt.Sprintf("/%s"+pathFormat, config.AppConfig.APIVersion, contentID)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"token":      token,
+			"expires_at": expiresAt,
+			"path":       path + "?preview=" + token,
+		},
+	})
+}
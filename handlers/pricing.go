@@ -1,49 +1,68 @@
 package handlers
 
 import (
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// PricingRequest is the body of PUT /pricing
+type PricingRequest struct {
+	Type               string `json:"type" validate:"required"`
+	Title              string `json:"title" validate:"required"`
+	TitleID            string `json:"title_id" validate:"required"`
+	Subtitle           string `json:"subtitle"`
+	SubtitleID         string `json:"subtitle_id"`
+	AdultPrice         int    `json:"adult_price" validate:"gte=0"`
+	InfantPrice        int    `json:"infant_price" validate:"gte=0"`
+	Currency           string `json:"currency"`
+	Description        string `json:"description"`
+	ImageURL           string `json:"image_url" validate:"omitempty,link"`
+	ThumbnailURL       string `json:"thumbnail_url" validate:"omitempty,link"`
+	PrimaryColor       string `json:"color" validate:"omitempty,hexcolor"`
+	StartGradientColor string `json:"start_gradient_color" validate:"omitempty,hexcolor"`
+	EndGradientColor   string `json:"end_gradient_color" validate:"omitempty,hexcolor"`
+}
+
+// GeneralPricingContentRequest is the body of PUT /pricing-content
+type GeneralPricingContentRequest struct {
+	GeneralPricingSectionTitlePart1    string `json:"general_pricing_section_title_part_1"`
+	GeneralPricingSectionTitlePart1ID  string `json:"general_pricing_section_title_part_1_id"`
+	GeneralPricingSectionTitlePart2    string `json:"general_pricing_section_title_part_2"`
+	GeneralPricingSectionTitlePart2ID  string `json:"general_pricing_section_title_part_2_id"`
+	GeneralPricingSectionDescription   string `json:"general_pricing_section_description"`
+	GeneralPricingSectionDescriptionID string `json:"general_pricing_section_description_id"`
+}
+
 // =============================================================================
 // PRICING MANAGEMENT - ADMIN
 // =============================================================================
 
 // UpdatePricing updates entrance fee pricing
 func UpdatePricing(c *fiber.Ctx) error {
-	var pricing models.Pricing
-	if err := c.BodyParser(&pricing); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req PricingRequest
+	if err := apierror.Bind(c, &req); err != nil {
+		return err
 	}
 
 	// Try to find existing pricing by type, create if not exists
-	var existingPricing models.Pricing
-	if err := config.DB.Where("type = ?", pricing.Type).First(&existingPricing).Error; err != nil {
+	var pricing models.Pricing
+	if err := config.DB.Where("type = ?", req.Type).First(&pricing).Error; err != nil {
 		// Create new pricing
+		applyRequest(&req, &pricing)
 		if err := config.DB.Create(&pricing).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create pricing",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create pricing")
 		}
 		return c.Status(fiber.StatusCreated).JSON(pricing)
 	}
 
 	// Update existing pricing
-	pricing.ID = existingPricing.ID
-	if err := config.DB.Save(&pricing).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update pricing",
-			"code":    "INTERNAL_ERROR",
-		})
+	before := revisionSnapshot(pricing)
+	applyRequest(&req, &pricing)
+	if err := saveWithRevision(c, "pricing", pricing.ID, before, &pricing); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update pricing"))
 	}
 
 	return c.JSON(pricing)
@@ -51,37 +70,27 @@ func UpdatePricing(c *fiber.Ctx) error {
 
 // UpdateGeneralPricingContent updates the general pricing content (singleton)
 func UpdateGeneralPricingContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.GeneralPricingContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req GeneralPricingContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.GeneralPricingContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create general pricing content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create general pricing content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update general pricing content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "pricing-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update general pricing content"))
 	}
 
 	return c.JSON(content)
@@ -96,11 +105,7 @@ func GetPricing(c *fiber.Ctx) error {
 	var pricings []models.Pricing
 
 	if err := config.DB.Find(&pricings).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch pricing data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch pricing data")
 	}
 
 	// Transform to the expected format
@@ -140,6 +145,7 @@ func GetGeneralPricingContent(c *fiber.Ctx) error {
 			"data": models.GeneralPricingContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
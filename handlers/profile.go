@@ -1,49 +1,63 @@
 package handlers
 
 import (
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
 )
 
+// ProfilePageContentRequest is the body of PUT /profile
+type ProfilePageContentRequest struct {
+	Title                  string         `json:"title"`
+	TitleID                string         `json:"title_id"`
+	HeaderImageURL         string         `json:"header_image_url" validate:"omitempty,link"`
+	Subtitle               string         `json:"subtitle"`
+	SubtitleID             string         `json:"subtitle_id"`
+	BriefSectionTitle      string         `json:"brief_section_title"`
+	BriefSectionTitleID    string         `json:"brief_section_title_id"`
+	BriefSectionContent    string         `json:"brief_section_content"`
+	BriefSectionContentID  string         `json:"brief_section_content_id"`
+	BriefSectionImageURL   string         `json:"brief_section_image_url" validate:"omitempty,link"`
+	ProfileSections        datatypes.JSON `json:"profile_sections"`
+	CTASectionTitle        string         `json:"cta_section_title"`
+	CTASectionTitleID      string         `json:"cta_section_title_id"`
+	CTASectionText         string         `json:"cta_section_text"`
+	CTASectionTextID       string         `json:"cta_section_text_id"`
+	CTASectionButtonText   string         `json:"cta_section_button_text"`
+	CTASectionButtonTextID string         `json:"cta_section_button_text_id"`
+	CTASectionButtonURL    string         `json:"cta_section_button_url" validate:"omitempty,link"`
+}
+
 // =============================================================================
 // PROFILE MANAGEMENT - ADMIN
 // =============================================================================
 
 // UpdateProfilePageContent updates village profile information
 func UpdateProfilePageContent(c *fiber.Ctx) error {
+	// Try to find existing profile, create if not exists
 	var profile models.ProfilePageContent
-	if err := c.BodyParser(&profile); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&profile).Error == nil
+	before := revisionSnapshot(profile)
+
+	var req ProfilePageContentRequest
+	if err := bindRequest(c, &req, &profile); err != nil {
+		return err
 	}
 
-	// Try to find existing profile, create if not exists
-	var existingProfile models.ProfilePageContent
-	if err := config.DB.First(&existingProfile).Error; err != nil {
+	if !exists {
 		// Create new profile
 		if err := config.DB.Create(&profile).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create profile",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create profile")
 		}
 		return c.Status(fiber.StatusCreated).JSON(profile)
 	}
 
 	// Update existing profile
-	profile.ID = existingProfile.ID
-	if err := config.DB.Save(&profile).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update profile",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "profile", profile.ID, before, &profile); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update profile"))
 	}
 
 	return c.JSON(profile)
@@ -58,13 +72,10 @@ func GetProfilePageContent(c *fiber.Ctx) error {
 	var profile models.ProfilePageContent
 
 	if err := config.DB.First(&profile).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Profile page content not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Profile page content not found")
 	}
 
+	setETag(c, profile.Version)
 	return c.JSON(fiber.Map{
 		"data": profile,
 	})
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// PUBLICATION HELPERS
+// =============================================================================
+
+// isAdminRequest reports whether the request went through the AdminAuth middleware
+func isAdminRequest(c *fiber.Ctx) bool {
+	_, ok := c.Locals("user").(models.User)
+	return ok
+}
+
+// publicationScope returns the visibility scope for list/detail queries.
+// Public requests only see live content; admin requests see everything and
+// may filter by status with ?status=draft,scheduled,...
+func publicationScope(c *fiber.Ctx) func(*gorm.DB) *gorm.DB {
+	if !isAdminRequest(c) {
+		return models.Published
+	}
+
+	statuses := splitQueryList(c.Query("status"))
+	return func(db *gorm.DB) *gorm.DB {
+		if len(statuses) == 0 {
+			return db
+		}
+		return db.Where("status IN ?", statuses)
+	}
+}
+
+// canViewContent reports whether the current request may see a single content item.
+// Live items are visible to everyone, admins see everything, and unpublished items are
+// visible with a valid ?preview= token issued for exactly this item.
+func canViewContent(c *fiber.Ctx, contentType string, contentID uint, publication models.Publication) bool {
+	if publication.IsLive(time.Now()) || isAdminRequest(c) {
+		return true
+	}
+
+	token := c.Query("preview")
+	if token == "" {
+		return false
+	}
+
+	claims, err := utils.ValidatePreviewToken(token)
+	if err != nil || claims.ContentType != contentType || claims.ContentID != contentID {
+		return false
+	}
+
+	// Previews must never be cached or indexed
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set("X-Robots-Tag", "noindex, nofollow")
+	return true
+}
+
+// splitQueryList splits a comma-separated query value into trimmed, non-empty parts
+func splitQueryList(value string) []string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(value, ",") {
+		trimmed := strings.TrimSpace(part)
+		if trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+	return parts
+}
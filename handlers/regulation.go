@@ -3,12 +3,49 @@ package handlers
 import (
 	"strconv"
 	"strings"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// RegulationRequest is the body of POST /regulations and PUT /regulations/:id
+type RegulationRequest struct {
+	models.Publication
+	CategoryID uint   `json:"category_id" validate:"required"`
+	Question   string `json:"question" validate:"required"`
+	QuestionID string `json:"question_id" validate:"required"`
+	Answer     string `json:"answer" validate:"required"`
+	AnswerID   string `json:"answer_id"`
+}
+
+// RegulationPageContentRequest is the body of PUT /regulations/content
+type RegulationPageContentRequest struct {
+	HeroImageURL          string `json:"hero_image_url" validate:"omitempty,link"`
+	HeroImageThumbnailURL string `json:"hero_image_thumbnail_url" validate:"omitempty,link"`
+	Title                 string `json:"title"`
+	TitleID               string `json:"title_id"`
+	Subtitle              string `json:"subtitle"`
+	SubtitleID            string `json:"subtitle_id"`
+	CTATitle              string `json:"cta_title"`
+	CTATitleID            string `json:"cta_title_id"`
+	CTADescription        string `json:"cta_description"`
+	CTADescriptionID      string `json:"cta_description_id"`
+	CTAButtonText         string `json:"cta_button_text"`
+	CTAButtonTextID       string `json:"cta_button_text_id"`
+	CTAButtonURL          string `json:"cta_button_url" validate:"omitempty,link"`
+}
+
+// RegulationCategoryRequest is the body of POST /regulations/categories and PUT /regulations/categories/:id
+type RegulationCategoryRequest struct {
+	Name          string `json:"name" validate:"required"`
+	NameID        string `json:"name_id" validate:"required"`
+	Description   string `json:"description"`
+	DescriptionID string `json:"description_id"`
+}
+
 // =============================================================================
 // REGULATION MANAGEMENT - ADMIN
 // =============================================================================
@@ -16,20 +53,17 @@ import (
 // CreateRegulation creates a new regulation
 func CreateRegulation(c *fiber.Ctx) error {
 	var regulation models.Regulation
-	if err := c.BodyParser(&regulation); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req RegulationRequest
+	if err := bindRequest(c, &req, &regulation); err != nil {
+		return err
+	}
+
+	if err := regulation.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
 	if err := config.DB.Create(&regulation).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create regulation",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create regulation")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(regulation)
@@ -41,27 +75,22 @@ func UpdateRegulation(c *fiber.Ctx) error {
 
 	var regulation models.Regulation
 	if err := config.DB.Where("id = ?", id).First(&regulation).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Regulation not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Regulation not found")
 	}
 
-	if err := c.BodyParser(&regulation); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(regulation)
+
+	var req RegulationRequest
+	if err := bindRequest(c, &req, &regulation); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&regulation).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update regulation",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := regulation.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "regulations", regulation.ID, before, &regulation); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update regulation"))
 	}
 
 	return c.JSON(regulation)
@@ -72,11 +101,7 @@ func DeleteRegulation(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.Regulation{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete regulation",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete regulation")
 	}
 
 	return c.JSON(fiber.Map{
@@ -87,37 +112,27 @@ func DeleteRegulation(c *fiber.Ctx) error {
 
 // UpdateRegulationPageContent updates the regulation page content (singleton)
 func UpdateRegulationPageContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.RegulationPageContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req RegulationPageContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.RegulationPageContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create regulation page content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create regulation page content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update regulation page content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "regulation-page-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update regulation page content"))
 	}
 
 	return c.JSON(content)
@@ -130,20 +145,13 @@ func UpdateRegulationPageContent(c *fiber.Ctx) error {
 // CreateRegulationCategory creates a new regulation category
 func CreateRegulationCategory(c *fiber.Ctx) error {
 	var category models.RegulationCategory
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req RegulationCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
 	if err := config.DB.Create(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create regulation category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create regulation category")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(category)
@@ -155,27 +163,18 @@ func UpdateRegulationCategory(c *fiber.Ctx) error {
 
 	var category models.RegulationCategory
 	if err := config.DB.Where("id = ?", id).First(&category).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Regulation category not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Regulation category not found")
 	}
 
-	if err := c.BodyParser(&category); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(category)
+
+	var req RegulationCategoryRequest
+	if err := bindRequest(c, &req, &category); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&category).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update regulation category",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "regulation-categories", category.ID, before, &category); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update regulation category"))
 	}
 
 	return c.JSON(category)
@@ -186,11 +185,7 @@ func DeleteRegulationCategory(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.RegulationCategory{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete regulation category",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete regulation category")
 	}
 
 	return c.JSON(fiber.Map{
@@ -207,6 +202,7 @@ func DeleteRegulationCategory(c *fiber.Ctx) error {
 func GetRegulations(c *fiber.Ctx) error {
 	var regulations []models.Regulation
 	query := config.DB.Model(&models.Regulation{}).
+		Scopes(publicationScope(c)).
 		Preload("RegulationCategory")
 
 	// Apply category filter
@@ -244,16 +240,12 @@ func GetRegulations(c *fiber.Ctx) error {
 	query = query.Limit(limit).Offset(offset)
 
 	if err := query.Order("created_at ASC").Find(&regulations).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch regulations data",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to fetch regulations data")
 	}
 
 	// Get total count (apply same filters)
 	var total int64
-	countQuery := config.DB.Model(&models.Regulation{})
+	countQuery := config.DB.Model(&models.Regulation{}).Scopes(publicationScope(c))
 	if category := c.Query("category"); category != "" {
 		cats := make([]string, 0)
 		for _, part := range strings.Split(category, ",") {
@@ -307,14 +299,11 @@ func GetRegulationByID(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	var regulation models.Regulation
-	if err := config.DB.Preload("RegulationCategory").Where("id = ?", id).First(&regulation).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Regulation not found",
-			"code":    "NOT_FOUND",
-		})
+	if err := config.DB.Preload("RegulationCategory").Where("id = ?", id).First(&regulation).Error; err != nil || !canViewContent(c, "regulations", regulation.ID, regulation.Publication) {
+		return apierror.NotFound("Regulation not found")
 	}
 
+	setETag(c, regulation.Version)
 	return c.JSON(fiber.Map{
 		"data": regulation,
 	})
@@ -325,7 +314,7 @@ func GetRegulationCategories(c *fiber.Ctx) error {
 	categories := getRegulationCategoriesFromTable()
 
 	var totalRegulations int64
-	config.DB.Model(&models.Regulation{}).Count(&totalRegulations)
+	config.DB.Model(&models.Regulation{}).Scopes(models.Published).Count(&totalRegulations)
 
 	return c.JSON(fiber.Map{
 		"data": categories,
@@ -345,6 +334,7 @@ func GetRegulationPageContent(c *fiber.Ctx) error {
 			"data": models.RegulationPageContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
@@ -360,7 +350,7 @@ func getRegulationCategoriesFromTable() []fiber.Map {
 	categories := make([]fiber.Map, 0, len(catRows))
 	for _, cat := range catRows {
 		var count int64
-		config.DB.Model(&models.Regulation{}).Where("category_id = ?", cat.ID).Count(&count)
+		config.DB.Model(&models.Regulation{}).Scopes(models.Published).Where("category_id = ?", cat.ID).Count(&count)
 
 		categories = append(categories, fiber.Map{
 			"id":             cat.ID,
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"reflect"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// =============================================================================
+// REQUEST HELPERS
+// =============================================================================
+
+// contentRequests maps each content type key to its request struct. The request structs hold the
+// validation rules, so PATCH and spreadsheet imports validate through them as POST and PUT do.
+var contentRequests = map[string]func() interface{}{
+	"carousel":                 func() interface{} { return &CarouselRequest{} },
+	"why-visit":                func() interface{} { return &WhyVisitRequest{} },
+	"selling-points":           func() interface{} { return &SellingPointRequest{} },
+	"attractions":              func() interface{} { return &AttractionRequest{} },
+	"pricing":                  func() interface{} { return &PricingRequest{} },
+	"destinations":             func() interface{} { return &DestinationRequest{} },
+	"destination-categories":   func() interface{} { return &DestinationCategoryRequest{} },
+	"gallery":                  func() interface{} { return &GalleryImageRequest{} },
+	"gallery-categories":       func() interface{} { return &GalleryCategoryRequest{} },
+	"regulations":              func() interface{} { return &RegulationRequest{} },
+	"regulation-categories":    func() interface{} { return &RegulationCategoryRequest{} },
+	"facilities":               func() interface{} { return &FacilityRequest{} },
+	"facility-categories":      func() interface{} { return &FacilityCategoryRequest{} },
+	"news":                     func() interface{} { return &NewsArticleRequest{} },
+	"news-categories":          func() interface{} { return &NewsCategoryRequest{} },
+	"news-authors":             func() interface{} { return &NewsAuthorRequest{} },
+	"heritage":                 func() interface{} { return &HeritageRequest{} },
+	"why-visit-content":        func() interface{} { return &GeneralWhyVisitContentRequest{} },
+	"attraction-content":       func() interface{} { return &GeneralAttractionContentRequest{} },
+	"pricing-content":          func() interface{} { return &GeneralPricingContentRequest{} },
+	"profile":                  func() interface{} { return &ProfilePageContentRequest{} },
+	"destination-page-content": func() interface{} { return &DestinationPageContentRequest{} },
+	"gallery-page-content":     func() interface{} { return &GalleryPageContentRequest{} },
+	"regulation-page-content":  func() interface{} { return &RegulationPageContentRequest{} },
+	"facility-page-content":    func() interface{} { return &FacilityPageContentRequest{} },
+	"news-page-content":        func() interface{} { return &NewsPageContentRequest{} },
+	"heritage-page-content":    func() interface{} { return &HeritagePageContentRequest{} },
+	"contact-info":             func() interface{} { return &ContactInfoRequest{} },
+	"contact-content":          func() interface{} { return &ContactContentRequest{} },
+}
+
+// validateModel checks model against the rules of the request struct of its content type
+func validateModel(ct models.ContentType, model interface{}) []apierror.FieldError {
+	req := contentRequests[ct.Key]()
+	copyRequestFields(req, model, false)
+	return apierror.ValidateFields(req)
+}
+
+// bindRequest parses the request body into req, the request struct of the endpoint, and copies
+// its fields onto model. req starts out with the values of model, so fields left out of the body
+// keep their stored values. Fields the request struct doesn't declare, such as the ID,
+// timestamps and version, can't be set by the body.
+func bindRequest(c *fiber.Ctx, req, model interface{}) error {
+	copyRequestFields(req, model, false)
+	if err := apierror.Bind(c, req); err != nil {
+		return err
+	}
+	copyRequestFields(req, model, true)
+	return nil
+}
+
+// applyRequest copies the fields of a parsed request struct onto model
+func applyRequest(req, model interface{}) {
+	copyRequestFields(req, model, true)
+}
+
+// copyRequestFields copies the fields declared by the request struct req between it and the
+// fields of the same name in model. Every request field must exist on the model with the same type.
+func copyRequestFields(req, model interface{}, toModel bool) {
+	src := reflect.ValueOf(req).Elem()
+	dst := reflect.ValueOf(model).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		field := dst.FieldByName(src.Type().Field(i).Name)
+		if toModel {
+			field.Set(src.Field(i))
+		} else {
+			src.Field(i).Set(field)
+		}
+	}
+}
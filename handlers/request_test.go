@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestRequestFieldsMatchModels(t *testing.T) {
+	for _, ct := range models.ContentTypes() {
+		newRequest, ok := contentRequests[ct.Key]
+		if !ok {
+			t.Errorf("content type %q has no request struct", ct.Key)
+			continue
+		}
+		reqType := reflect.TypeOf(newRequest()).Elem()
+		modelType := reflect.TypeOf(ct.New()).Elem()
+		t.Run(reqType.Name(), func(t *testing.T) {
+			for i := 0; i < reqType.NumField(); i++ {
+				field := reqType.Field(i)
+				switch field.Name {
+				case "ID", "BaseModel", "CreatedAt", "UpdatedAt", "DeletedAt", "Version":
+					t.Errorf("%s declares %s, which the body must not set", reqType.Name(), field.Name)
+					continue
+				}
+				modelField, ok := modelType.FieldByName(field.Name)
+				if !ok {
+					t.Errorf("%s.%s has no field on %s", reqType.Name(), field.Name, modelType.Name())
+					continue
+				}
+				if modelField.Type != field.Type {
+					t.Errorf("%s.%s is %s, %s.%s is %s", reqType.Name(), field.Name, field.Type,
+						modelType.Name(), field.Name, modelField.Type)
+				}
+				if jsonName(field) != jsonName(modelField) {
+					t.Errorf("%s.%s is %q in JSON, on the model it is %q", reqType.Name(), field.Name, jsonName(field), jsonName(modelField))
+				}
+			}
+		})
+	}
+}
+
+func jsonName(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+func TestBindRequestKeepsProtectedFields(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	carousel := models.Carousel{Title: "Old title", TitleID: "Judul lama", Subtitle: "Kept subtitle"}
+	carousel.ID = 7
+	carousel.CreatedAt = created
+	carousel.Version = 3
+
+	app := fiber.New()
+	app.Post("/", func(c *fiber.Ctx) error {
+		var req CarouselRequest
+		return bindRequest(c, &req, &carousel)
+	})
+
+	body := `{"id": 99, "version": 42, "created_at": "2030-01-01T00:00:00Z", "title": "New title", "image_url": "https://cdn.example.com/a.webp"}`
+	request := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != fiber.StatusOK {
+		message, _ := io.ReadAll(response.Body)
+		t.Fatalf("status = %d, want 200: %s", response.StatusCode, message)
+	}
+
+	if carousel.ID != 7 || carousel.Version != 3 || !carousel.CreatedAt.Equal(created) {
+		t.Errorf("protected fields changed: id %d, version %d, created_at %s", carousel.ID, carousel.Version, carousel.CreatedAt)
+	}
+	if carousel.Title != "New title" || carousel.ImageURL != "https://cdn.example.com/a.webp" {
+		t.Errorf("title = %q, image_url = %q, want the values from the body", carousel.Title, carousel.ImageURL)
+	}
+	if carousel.Subtitle != "Kept subtitle" {
+		t.Errorf("subtitle = %q, want the stored value kept", carousel.Subtitle)
+	}
+}
+
+func TestValidateModelUsesRequestRules(t *testing.T) {
+	ct, _ := models.LookupContentType("carousel")
+	carousel := models.Carousel{Title: "Title", ImageURL: "not a link"}
+
+	fields := map[string]bool{}
+	for _, field := range validateModel(ct, &carousel) {
+		fields[field.Field] = true
+	}
+	if !fields["title_id"] || !fields["image_url"] || fields["title"] || len(fields) != 2 {
+		t.Errorf("errors on %v, want title_id and image_url", fields)
+	}
+}
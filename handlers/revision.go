@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/json"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// =============================================================================
+// REVISION HELPERS
+// =============================================================================
+
+// revisionSnapshot serializes a model to JSON for storing as a revision
+func revisionSnapshot(model interface{}) []byte {
+	snapshot, err := json.Marshal(model)
+	if err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// recordRevision stores a snapshot of the saved model. If the row has no revisions yet,
+// the state before this change is stored first as a baseline so it can be restored.
+func recordRevision(tx *gorm.DB, c *fiber.Ctx, contentType string, contentID uint, before []byte, action string, after interface{}) error {
+	var lastVersion int
+	if err := tx.Model(&models.ContentRevision{}).
+		Where("content_type = ? AND content_id = ?", contentType, contentID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&lastVersion).Error; err != nil {
+		return err
+	}
+
+	if lastVersion == 0 && before != nil {
+		baseline := models.ContentRevision{
+			ContentType: contentType,
+			ContentID:   contentID,
+			Version:     1,
+			Action:      models.RevisionActionBaseline,
+			Snapshot:    datatypes.JSON(before),
+		}
+		if err := tx.Create(&baseline).Error; err != nil {
+			return err
+		}
+		lastVersion = 1
+	}
+
+	revision := models.ContentRevision{
+		ContentType: contentType,
+		ContentID:   contentID,
+		Version:     lastVersion + 1,
+		Action:      action,
+		Snapshot:    datatypes.JSON(revisionSnapshot(after)),
+	}
+	if user, ok := c.Locals("user").(models.User); ok {
+		revision.AuthorID = &user.ID
+		revision.AuthorUsername = user.Username
+	}
+
+	return tx.Create(&revision).Error
+}
+
+// saveWithRevision saves the model and records a revision in a single transaction.
+// The If-Match header must carry the stored version; otherwise an APIError is returned.
+// Images the update replaced are deleted from R2 once the transaction has committed.
+func saveWithRevision(c *fiber.Ctx, contentType string, contentID uint, before []byte, model interface{}) error {
+	if err := models.RetryOnSlugConflict(func() error {
+		return saveRevisionTx(c, contentType, contentID, before, model)
+	}); err != nil {
+		return err
+	}
+
+	deleteReplacedImages(before, model)
+	return nil
+}
+
+// saveRevisionTx is the transaction of saveWithRevision
+func saveRevisionTx(c *fiber.Ctx, contentType string, contentID uint, before []byte, model interface{}) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		version, err := nextVersion(tx, c, model, contentID)
+		if err != nil {
+			return err
+		}
+		setModelVersion(model, version)
+
+		if err := tx.Save(model).Error; err != nil {
+			return err
+		}
+		if err := recordRevision(tx, c, contentType, contentID, before, models.RevisionActionUpdate, model); err != nil {
+			return err
+		}
+
+		setETag(c, version)
+		return nil
+	})
+}
+
+// =============================================================================
+// REVISION MANAGEMENT - ADMIN
+// =============================================================================
+
+// GetRevisions lists the revisions of a content item (without snapshots)
+func GetRevisions(c *fiber.Ctx) error {
+	contentType, ok := models.LookupContentType(c.Params("type"))
+	if !ok {
+		return apierror.NotFound("Unknown content type")
+	}
+
+	contentID, err := c.ParamsInt("id")
+	if err != nil || contentID <= 0 {
+		return apierror.BadRequest("Invalid content ID")
+	}
+
+	var revisions []models.ContentRevision
+	if err := config.DB.
+		Omit("snapshot").
+		Where("content_type = ? AND content_id = ?", contentType.Key, contentID).
+		Order("version DESC").
+		Find(&revisions).Error; err != nil {
+		return apierror.Internal("Failed to fetch revisions")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": revisions,
+		"meta": fiber.Map{
+			"total":        len(revisions),
+			"content_type": contentType.Key,
+			"content_id":   contentID,
+		},
+	})
+}
+
+// GetRevision returns a single revision including its snapshot
+func GetRevision(c *fiber.Ctx) error {
+	revision, err := findRevision(c)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": revision,
+	})
+}
+
+// DiffRevisions returns a field-level diff between two revisions.
+// Use ?from=<revision_id>&to=<revision_id|current>; "to" defaults to the current row.
+func DiffRevisions(c *fiber.Ctx) error {
+	contentType, ok := models.LookupContentType(c.Params("type"))
+	if !ok {
+		return apierror.NotFound("Unknown content type")
+	}
+	contentID := c.Params("id")
+
+	var from models.ContentRevision
+	if err := config.DB.
+		Where("id = ? AND content_type = ? AND content_id = ?", c.Query("from"), contentType.Key, contentID).
+		First(&from).Error; err != nil {
+		return apierror.NotFound("Source revision not found")
+	}
+
+	var toSnapshot []byte
+	to := c.Query("to", "current")
+	if to == "current" {
+		current := contentType.New()
+		if err := config.DB.Where("id = ?", contentID).First(current).Error; err != nil {
+			return apierror.NotFound("Content not found")
+		}
+		toSnapshot = revisionSnapshot(current)
+	} else {
+		var toRevision models.ContentRevision
+		if err := config.DB.
+			Where("id = ? AND content_type = ? AND content_id = ?", to, contentType.Key, contentID).
+			First(&toRevision).Error; err != nil {
+			return apierror.NotFound("Target revision not found")
+		}
+		toSnapshot = toRevision.Snapshot
+	}
+
+	changes, err := utils.DiffJSON(from.Snapshot, toSnapshot, "created_at", "updated_at")
+	if err != nil {
+		return apierror.Internal("Failed to compute diff: " + err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"data": changes,
+		"meta": fiber.Map{
+			"from":          from.ID,
+			"to":            to,
+			"total_changes": len(changes),
+		},
+	})
+}
+
+// RestoreRevision overwrites the content row with the snapshot of an old revision.
+// The restore itself is recorded as a new revision so it can be undone. Like an update, it
+// needs the If-Match header and is rejected with 409 when the content has changed since.
+func RestoreRevision(c *fiber.Ctx) error {
+	revision, err := findRevision(c)
+	if err != nil {
+		return err
+	}
+
+	contentType, _ := models.LookupContentType(revision.ContentType)
+
+	current := contentType.New()
+	if err := config.DB.Where("id = ?", revision.ContentID).First(current).Error; err != nil {
+		return apierror.NotFound("Content not found")
+	}
+
+	restored := contentType.New()
+	if err := json.Unmarshal(revision.Snapshot, restored); err != nil {
+		return apierror.Internal("Failed to read revision snapshot")
+	}
+
+	var before []byte
+	if err := models.RetryOnSlugConflict(func() error {
+		return config.DB.Transaction(func(tx *gorm.DB) error {
+			// Lock the row so concurrent saves and restores number their revisions one after another,
+			// and only restore over the version the editor is looking at
+			version, err := nextVersion(tx, c, current, revision.ContentID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Where("id = ?", revision.ContentID).First(current).Error; err != nil {
+				return err
+			}
+			before = revisionSnapshot(current)
+
+			// Restoring is a change of its own, so the row moves on to a new version
+			setModelVersion(restored, version)
+
+			// Associations in the snapshot are read-only copies; only restore the row itself
+			if err := tx.Omit(clause.Associations).Save(restored).Error; err != nil {
+				return err
+			}
+			return recordRevision(tx, c, revision.ContentType, revision.ContentID, before, models.RevisionActionRestore, restored)
+		})
+	}); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to restore revision: "+err.Error()))
+	}
+
+	setETag(c, modelVersion(restored))
+	deleteReplacedImages(before, restored)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Revision restored successfully",
+		"data":    restored,
+	})
+}
+
+// findRevision loads the revision addressed by :type, :id and :revisionId
+func findRevision(c *fiber.Ctx) (*models.ContentRevision, error) {
+	contentType, ok := models.LookupContentType(c.Params("type"))
+	if !ok {
+		return nil, apierror.NotFound("Unknown content type")
+	}
+
+	var revision models.ContentRevision
+	if err := config.DB.
+		Where("id = ? AND content_type = ? AND content_id = ?", c.Params("revisionId"), contentType.Key, c.Params("id")).
+		First(&revision).Error; err != nil {
+		return nil, apierror.NotFound("Revision not found")
+	}
+
+	return &revision, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"strconv"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 	"yaro-wora-be/utils"
@@ -23,11 +24,7 @@ func SearchUsers(c *fiber.Ctx) error {
 	}
 
 	if err := query.Find(&users).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to search users",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to search users")
 	}
 
 	return c.JSON(fiber.Map{
@@ -149,21 +146,13 @@ func GetUserByUsernameOrEmail(c *fiber.Ctx) error {
 		// Note: We don't have email in User model, but this shows the pattern
 		err := config.DB.Where("username = ?", identifier).First(&user).Error
 		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "User not found",
-				"code":    "NOT_FOUND",
-			})
+			return apierror.NotFound("User not found")
 		}
 	} else {
 		// Search by username (citext field - case insensitive)
 		err := utils.Search.UsernameSearch(config.DB, identifier).First(&user).Error
 		if err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error":   true,
-				"message": "User not found",
-				"code":    "NOT_FOUND",
-			})
+			return apierror.NotFound("User not found")
 		}
 	}
 
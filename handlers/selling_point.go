@@ -1,12 +1,29 @@
 package handlers
 
 import (
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// SellingPointRequest is the body of POST /selling-points and PUT /selling-points/:id
+type SellingPointRequest struct {
+	models.Publication
+	Title             string `json:"title" validate:"required"`
+	TitleID           string `json:"title_id" validate:"required"`
+	Description       string `json:"description"`
+	DescriptionID     string `json:"description_id"`
+	ImageURL          string `json:"image_url" validate:"required,link"`
+	ThumbnailURL      string `json:"thumbnail_url" validate:"omitempty,link"`
+	PillarColor       string `json:"pillar_color" validate:"omitempty,hexcolor"`
+	TextColor         string `json:"text_color" validate:"omitempty,hexcolor"`
+	SellingPointOrder int    `json:"selling_point_order" validate:"gte=0"`
+	IsActive          bool   `json:"is_active"`
+}
+
 // =============================================================================
 // SELLING POINTS MANAGEMENT - ADMIN
 // =============================================================================
@@ -14,20 +31,17 @@ import (
 // CreateSellingPoint creates a new selling point
 func CreateSellingPoint(c *fiber.Ctx) error {
 	var sellingPoint models.SellingPoint
-	if err := c.BodyParser(&sellingPoint); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req SellingPointRequest
+	if err := bindRequest(c, &req, &sellingPoint); err != nil {
+		return err
+	}
+
+	if err := sellingPoint.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
 	if err := config.DB.Create(&sellingPoint).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create selling point",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create selling point")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(sellingPoint)
@@ -39,27 +53,22 @@ func UpdateSellingPoint(c *fiber.Ctx) error {
 
 	var sellingPoint models.SellingPoint
 	if err := config.DB.Where("id = ?", id).First(&sellingPoint).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Selling point not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Selling point not found")
+	}
+
+	before := revisionSnapshot(sellingPoint)
+
+	var req SellingPointRequest
+	if err := bindRequest(c, &req, &sellingPoint); err != nil {
+		return err
 	}
 
-	if err := c.BodyParser(&sellingPoint); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	if err := sellingPoint.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
-	if err := config.DB.Save(&sellingPoint).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update selling point",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "selling-points", sellingPoint.ID, before, &sellingPoint); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update selling point"))
 	}
 
 	return c.JSON(sellingPoint)
@@ -70,11 +79,7 @@ func DeleteSellingPoint(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.SellingPoint{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete selling point",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete selling point")
 	}
 
 	return c.JSON(fiber.Map{
@@ -91,12 +96,8 @@ func DeleteSellingPoint(c *fiber.Ctx) error {
 func GetSellingPoints(c *fiber.Ctx) error {
 	var sellingPoints []models.SellingPoint
 
-	if err := config.DB.Where("is_active = ?", true).Order("selling_point_order ASC, created_at ASC").Find(&sellingPoints).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch selling points data",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := config.DB.Scopes(publicationScope(c)).Where("is_active = ?", true).Order("selling_point_order ASC, created_at ASC").Find(&sellingPoints).Error; err != nil {
+		return apierror.Internal("Failed to fetch selling points data")
 	}
 
 	return c.JSON(fiber.Map{
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// resolveDetailParam maps the :id route parameter, which may be a numeric ID, a slug in
+// either language or an old slug, to the content ID. Unknown slugs resolve to "0" so the
+// caller's lookup falls through to its usual 404. For old slugs, redirectTo holds the
+// canonical URL of the content; old slugs of content the request can't see resolve to "0".
+func resolveDetailParam(c *fiber.Ctx, contentType string) (id string, redirectTo string) {
+	param := c.Params("id")
+	if _, err := strconv.ParseUint(param, 10, 64); err == nil {
+		return param, ""
+	}
+
+	contentID, canonical, err := models.ResolveSlug(config.DB, contentType, param, publicationScope(c))
+	if err != nil {
+		return "0", ""
+	}
+
+	if canonical != "" {
+		path := c.Path()
+		redirectTo = path[:strings.LastIndex(path, "/")+1] + canonical
+		if query := string(c.Request().URI().QueryString()); query != "" {
+			redirectTo += "?" + query
+		}
+	}
+
+	return strconv.FormatUint(uint64(contentID), 10), redirectTo
+}
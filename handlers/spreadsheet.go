@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Spreadsheet row outcomes
+const (
+	SpreadsheetActionCreated = "created"
+	SpreadsheetActionUpdated = "updated"
+)
+
+// SpreadsheetRowError describes why a spreadsheet row can't be imported
+type SpreadsheetRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// SpreadsheetRowResult reports what happened to a spreadsheet row
+type SpreadsheetRowResult struct {
+	Row    int    `json:"row"`
+	ID     uint   `json:"id,omitempty"`
+	Label  string `json:"label"`
+	Action string `json:"action"`
+}
+
+// spreadsheetRowImporter turns a row into a model that is ready to be saved. existing is true when
+// the row updates a row that is already in the database. Field errors are reported per row.
+type spreadsheetRowImporter func(tx *gorm.DB, row utils.SpreadsheetRow) (model interface{}, label string, existing bool, fields []apierror.FieldError, err error)
+
+var errSpreadsheetRollback = errors.New("spreadsheet import rolled back")
+
+// =============================================================================
+// SPREADSHEET IMPORT - ADMIN
+// =============================================================================
+
+// ImportRegulationsSpreadsheet imports regulations (FAQ) from a CSV or XLSX file.
+// Columns: category, question, question_id, answer, answer_id and optionally status.
+// Rows whose question matches an existing regulation update it. Use ?dry_run=true to only validate.
+func ImportRegulationsSpreadsheet(c *fiber.Ctx) error {
+	categories := spreadsheetCategoryResolver(&models.RegulationCategory{})
+
+	return importSpreadsheet(c, "regulations", func(tx *gorm.DB, row utils.SpreadsheetRow) (interface{}, string, bool, []apierror.FieldError, error) {
+		question := row.Get("question", "question_en")
+
+		var regulation models.Regulation
+		existing, err := findSpreadsheetMatch(tx, &regulation, "question", question)
+		if err != nil {
+			return nil, question, false, nil, err
+		}
+
+		regulation.Question = question
+		regulation.QuestionID = row.Get("question_id")
+		regulation.Answer = row.Get("answer", "answer_en")
+		regulation.AnswerID = row.Get("answer_id")
+
+		var fields []apierror.FieldError
+		if regulation.CategoryID, err = categories(tx, row); err != nil {
+			fields = append(fields, apierror.FieldError{Field: "category", Message: err.Error()})
+		}
+		fields = append(fields, applySpreadsheetStatus(&regulation.Publication, row)...)
+
+		return &regulation, question, existing, fields, nil
+	})
+}
+
+// ImportGallerySpreadsheet imports gallery image metadata from a CSV or XLSX file.
+// Columns: image_url, category, title, title_id, short_description, short_description_id,
+// description, description_id, photographer, location, tags, tags_id and optionally status.
+// Tags are separated by commas or semicolons. Rows whose image_url matches an existing image update it.
+// Use ?dry_run=true to only validate.
+func ImportGallerySpreadsheet(c *fiber.Ctx) error {
+	categories := spreadsheetCategoryResolver(&models.GalleryCategory{})
+
+	return importSpreadsheet(c, "gallery", func(tx *gorm.DB, row utils.SpreadsheetRow) (interface{}, string, bool, []apierror.FieldError, error) {
+		title := row.Get("title", "title_en")
+
+		var image models.GalleryImage
+		existing, err := findSpreadsheetMatch(tx, &image, "image_url", row.Get("image_url"))
+		if err != nil {
+			return nil, title, false, nil, err
+		}
+
+		image.ImageURL = row.Get("image_url")
+		image.Title = title
+		image.TitleID = row.Get("title_id")
+		image.ShortDescription = row.Get("short_description", "short_description_en")
+		image.ShortDescriptionID = row.Get("short_description_id")
+		image.Description = row.Get("description", "description_en")
+		image.DescriptionID = row.Get("description_id")
+		image.Photographer = row.Get("photographer")
+		image.Location = row.Get("location")
+		image.Tags = spreadsheetTags(row.Get("tags", "tags_en"))
+		image.TagsID = spreadsheetTags(row.Get("tags_id"))
+		if !existing {
+			prefillFromCaptureData(tx, &image)
+			if image.DateUploaded.IsZero() {
+				image.DateUploaded = time.Now()
+			}
+		}
+
+		var fields []apierror.FieldError
+		if image.CategoryID, err = categories(tx, row); err != nil {
+			fields = append(fields, apierror.FieldError{Field: "category", Message: err.Error()})
+		}
+		fields = append(fields, applySpreadsheetStatus(&image.Publication, row)...)
+
+		return &image, title, existing, fields, nil
+	})
+}
+
+// importSpreadsheet reads the uploaded file and imports every row in a single transaction.
+// Every row is validated; when any row fails, or on a dry run, nothing is written.
+func importSpreadsheet(c *fiber.Ctx, contentType string, importRow spreadsheetRowImporter) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return apierror.BadRequest("No file uploaded")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return apierror.BadRequest("Failed to read file")
+	}
+	defer file.Close()
+
+	rows, err := utils.ReadSpreadsheet(fileHeader.Filename, file)
+	if err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+	if len(rows) == 0 {
+		return apierror.BadRequest("The file has no data rows")
+	}
+
+	ct, _ := models.LookupContentType(contentType)
+	dryRun := c.QueryBool("dry_run")
+	results := make([]SpreadsheetRowResult, 0, len(rows))
+	rowErrors := []SpreadsheetRowError{}
+	counts := map[string]int{}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			model, label, existing, fields, err := importRow(tx, row)
+			if err != nil {
+				return err
+			}
+
+			fields = append(fields, validateModel(ct, model)...)
+			if len(fields) > 0 {
+				for _, field := range fields {
+					rowErrors = append(rowErrors, SpreadsheetRowError{Row: row.Number, Field: field.Field, Message: field.Message})
+				}
+				continue
+			}
+
+			action := SpreadsheetActionCreated
+			if existing {
+				action = SpreadsheetActionUpdated
+				setModelVersion(model, modelVersion(model)+1)
+			}
+
+			// Each row gets a savepoint so a failing row is reported instead of aborting the transaction
+			if err := models.RetryOnSlugConflict(func() error {
+				return tx.Transaction(func(rowTx *gorm.DB) error {
+					return rowTx.Omit(clause.Associations).Save(model).Error
+				})
+			}); err != nil {
+				rowErrors = append(rowErrors, SpreadsheetRowError{Row: row.Number, Message: err.Error()})
+				continue
+			}
+
+			results = append(results, SpreadsheetRowResult{Row: row.Number, ID: modelID(model), Label: label, Action: action})
+			counts[action]++
+		}
+
+		if len(rowErrors) > 0 || dryRun {
+			return errSpreadsheetRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSpreadsheetRollback) {
+		return apierror.Internal("Failed to import spreadsheet")
+	}
+
+	if len(rowErrors) > 0 {
+		return apierror.New(fiber.StatusUnprocessableEntity, apierror.CodeValidation,
+			fmt.Sprintf("%d row(s) have errors; nothing was imported", countErrorRows(rowErrors))).
+			With("errors", rowErrors)
+	}
+
+	message := fmt.Sprintf("%d %s row(s) imported", len(results), strings.ToLower(ct.Name))
+	if dryRun {
+		message = fmt.Sprintf("%d %s row(s) are valid; nothing was imported (dry run)", len(results), strings.ToLower(ct.Name))
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data":    results,
+		"meta": fiber.Map{
+			"total":   len(results),
+			"created": counts[SpreadsheetActionCreated],
+			"updated": counts[SpreadsheetActionUpdated],
+			"dry_run": dryRun,
+		},
+	})
+}
+
+// spreadsheetCategoryResolver returns a function resolving the category column of a row
+// by its English or Indonesian name, caching lookups for the duration of the import
+func spreadsheetCategoryResolver(category interface{}) func(tx *gorm.DB, row utils.SpreadsheetRow) (uint, error) {
+	cache := map[string]uint{}
+
+	return func(tx *gorm.DB, row utils.SpreadsheetRow) (uint, error) {
+		name := row.Get("category", "category_name")
+		if name == "" {
+			return 0, errors.New("is required")
+		}
+
+		key := strings.ToLower(name)
+		if id, ok := cache[key]; ok {
+			return id, nil
+		}
+
+		var ids []uint
+		if err := tx.Model(category).
+			Where("LOWER(name) = ? OR LOWER(name_id) = ?", key, key).
+			Limit(1).
+			Pluck("id", &ids).Error; err != nil {
+			return 0, err
+		}
+		if len(ids) == 0 {
+			return 0, fmt.Errorf("no category named %q", name)
+		}
+
+		cache[key] = ids[0]
+		return ids[0], nil
+	}
+}
+
+// findSpreadsheetMatch loads the row whose column equals value into model and reports whether it exists
+func findSpreadsheetMatch(tx *gorm.DB, model interface{}, column, value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	err := tx.Where(column+" = ?", value).First(model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// applySpreadsheetStatus applies the optional status column; new rows default to published
+func applySpreadsheetStatus(publication *models.Publication, row utils.SpreadsheetRow) []apierror.FieldError {
+	if status := strings.ToLower(row.Get("status")); status != "" {
+		publication.Status = status
+	}
+	if err := publication.Normalize(time.Now()); err != nil {
+		return []apierror.FieldError{{Field: "status", Message: err.Error()}}
+	}
+	return nil
+}
+
+// spreadsheetTags converts a tag list cell into a JSON array
+func spreadsheetTags(value string) datatypes.JSON {
+	data, _ := json.Marshal(utils.SplitList(value))
+	return datatypes.JSON(data)
+}
+
+// countErrorRows counts the distinct rows in an error report
+func countErrorRows(rowErrors []SpreadsheetRowError) int {
+	rows := map[int]bool{}
+	for _, rowError := range rowErrors {
+		rows[rowError.Row] = true
+	}
+	return len(rows)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+	"yaro-wora-be/apierror"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// TRASH MANAGEMENT - ADMIN
+// =============================================================================
+
+// GetTrash lists soft-deleted content across all content types.
+// Use ?type=<key> to only list one content type.
+func GetTrash(c *fiber.Ctx) error {
+	var contentTypes []models.ContentType
+	if key := c.Query("type"); key != "" {
+		ct, ok := models.LookupContentType(key)
+		if !ok || ct.Singleton {
+			return apierror.BadRequest("Unknown content type")
+		}
+		contentTypes = append(contentTypes, ct)
+	} else {
+		for _, ct := range models.ContentTypes() {
+			if !ct.Singleton {
+				contentTypes = append(contentTypes, ct)
+			}
+		}
+	}
+
+	type trashEntry struct {
+		models.TrashedItem
+		PurgeAt *time.Time `json:"purge_at"`
+	}
+
+	retention := config.AppConfig.TrashRetentionDays
+	entries := []trashEntry{}
+	for _, ct := range contentTypes {
+		items, err := models.ListTrashed(config.DB, ct)
+		if err != nil {
+			return apierror.Internal("Failed to fetch trash")
+		}
+
+		for _, item := range items {
+			entry := trashEntry{TrashedItem: item}
+			if retention > 0 {
+				purgeAt := item.DeletedAt.AddDate(0, 0, retention)
+				entry.PurgeAt = &purgeAt
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": entries,
+		"meta": fiber.Map{
+			"total":          len(entries),
+			"retention_days": retention,
+		},
+	})
+}
+
+// RestoreTrashItem moves a soft-deleted item back out of the trash
+func RestoreTrashItem(c *fiber.Ctx) error {
+	ct, id, err := trashTarget(c)
+	if err != nil {
+		return err
+	}
+
+	if err := models.RestoreTrashed(config.DB, ct, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NotFound("Item not found in trash")
+		}
+		return apierror.Internal("Failed to restore item")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": ct.Name + " restored successfully",
+	})
+}
+
+// PurgeTrashItem permanently deletes an item from the trash, including its images in R2
+func PurgeTrashItem(c *fiber.Ctx) error {
+	ct, id, err := trashTarget(c)
+	if err != nil {
+		return err
+	}
+
+	row, err := models.PurgeTrashed(config.DB, ct, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return apierror.NotFound("Item not found in trash")
+		case errors.Is(err, models.ErrTrashItemInUse):
+			return apierror.Conflict(ct.Name + " is still used by other content (including trashed content)")
+		}
+		return apierror.Internal("Failed to purge item")
+	}
+
+	// Images are only removed once the row is gone for good
+	models.DeleteUnreferencedImages(config.DB, row)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": ct.Name + " permanently deleted",
+	})
+}
+
+// trashTarget resolves :type and :id for trash operations
+func trashTarget(c *fiber.Ctx) (models.ContentType, uint, error) {
+	ct, ok := models.LookupContentType(c.Params("type"))
+	if !ok || ct.Singleton {
+		return ct, 0, apierror.NotFound("Unknown content type")
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return ct, 0, apierror.BadRequest("Invalid ID")
+	}
+
+	return ct, uint(id), nil
+}
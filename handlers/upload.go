@@ -1,61 +1,415 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
+	"yaro-wora-be/models"
 	"yaro-wora-be/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
 )
 
+// UploadContentResponse is the upload result together with its media library record
+type UploadContentResponse struct {
+	*utils.UploadResponse
+	Asset     *models.MediaAsset `json:"asset,omitempty"`
+	Duplicate bool               `json:"duplicate"` // The same file was uploaded before and its URLs are returned instead
+}
+
+// requireStorage fails requests that need storage when no backend could be initialized
+func requireStorage() error {
+	if utils.Storage == nil {
+		return apierror.New(fiber.StatusServiceUnavailable, apierror.CodeStorageUnavailable, "File storage is not available")
+	}
+	return nil
+}
+
 // =============================================================================
 // CONTENT UPLOAD - ADMIN
 // =============================================================================
 
-// UploadContent handles file uploads to Cloudflare R2
+// UploadContent handles file uploads to the configured storage backend
 func UploadContent(c *fiber.Ctx) error {
+	if err := requireStorage(); err != nil {
+		return err
+	}
 
 	// Get uploaded file
 	file, err := c.FormFile("file")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "No file uploaded",
-			"code":    "BAD_REQUEST",
-		})
+		return apierror.BadRequest("No file uploaded")
 	}
 
 	// Validate file size
 	maxSize := int64(config.AppConfig.MaxFileUploadSize)
 	if file.Size > maxSize {
-		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-			"error":   true,
-			"message": fmt.Sprintf("File size exceeds maximum limit of %d MB", maxSize/(1024*1024)),
-			"code":    "FILE_TOO_LARGE",
-		})
+		return apierror.New(fiber.StatusRequestEntityTooLarge, apierror.CodeFileTooLarge, fmt.Sprintf("File size exceeds maximum limit of %d MB", maxSize/(1024*1024)))
+	}
+
+	// Determine upload folder based on content type or form field
+	folder := c.FormValue("folder", "uploads")
+	if err := validateFolder(folder); err != nil {
+		return err
+	}
+
+	// Images get a thumbnail and variants, videos a poster frame; media_type=panorama keeps
+	// 360° images at full resolution
+	mediaType := c.FormValue("media_type")
+	if err := validateMediaType(mediaType); err != nil {
+		return err
+	}
+
+	content, err := utils.ReadMultipartFile(file)
+	if err != nil {
+		return apierror.Internal("Failed to upload file: " + err.Error())
+	}
+
+	// The same file uploaded again reuses the stored one, unless force=true
+	if c.FormValue("force") != "true" {
+		if existing := models.FindDuplicateAsset(config.DB, utils.ContentHash(content), mediaType); existing != nil {
+			return c.JSON(UploadContentResponse{UploadResponse: existing.UploadResponse(), Asset: existing, Duplicate: true})
+		}
 	}
 
 	// Check storage limit before upload
 	if err := utils.Storage.CheckStorageLimit(file.Size); err != nil {
-		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
-			"error":   true,
-			"message": err.Error(),
-			"code":    "STORAGE_LIMIT_EXCEEDED",
-		})
+		return apierror.New(fiber.StatusRequestEntityTooLarge, apierror.CodeStorageLimitExceeded, err.Error())
 	}
 
-	// Determine upload folder based on content type or form field
-	folder := c.FormValue("folder", "uploads")
+	uploadResponse, err := utils.Storage.UploadMediaContent(content, file.Filename, folder, mediaType)
+	if err != nil {
+		return uploadError(err)
+	}
+
+	// Keep a record of the upload for the media library
+	asset := recordMediaAsset(c, uploadResponse, folder, file.Filename, c.FormValue("alt_text"), c.FormValue("alt_text_id"))
+	return c.JSON(UploadContentResponse{UploadResponse: uploadResponse, Asset: asset})
+}
+
+// =============================================================================
+// PRESIGNED UPLOAD - ADMIN
+// =============================================================================
+
+// CreateMediaUploadRequest describes a file the client will upload straight to storage
+type CreateMediaUploadRequest struct {
+	Filename    string `json:"filename" validate:"required,max=255"`
+	ContentType string `json:"content_type" validate:"required"` // Checked against UPLOAD_ALLOWED_TYPES
+	Size        int64  `json:"size" validate:"gt=0"`
+	Folder      string `json:"folder"`
+	MediaType   string `json:"media_type" validate:"omitempty,oneof=image video panorama"`
+	ContentHash string `json:"content_hash" validate:"omitempty,len=64,hexadecimal"` // SHA-256, skips the upload of files stored already
+	AltText     string `json:"alt_text"`
+	AltTextID   string `json:"alt_text_id"`
+}
+
+// CreateMediaUpload reserves an upload slot and returns a presigned URL the file can be
+// PUT to directly, for files too large to go through the API. When the request carries the
+// content hash of a file uploaded before, that asset is returned and no slot is created.
+func CreateMediaUpload(c *fiber.Ctx) error {
+	if err := requireStorage(); err != nil {
+		return err
+	}
+
+	var req CreateMediaUploadRequest
+	if err := apierror.Bind(c, &req); err != nil {
+		return err
+	}
+	if !utils.Storage.UploadTypeAllowed(req.ContentType) {
+		return apierror.New(fiber.StatusUnsupportedMediaType, apierror.CodeUnsupportedFileType, req.ContentType+" files can't be uploaded")
+	}
+	if utils.ContentTypeFromFilename(req.Filename) != req.ContentType {
+		return apierror.Validation([]apierror.FieldError{{Field: "filename", Message: "extension must match content_type"}})
+	}
+	if req.Folder == "" {
+		req.Folder = "uploads"
+	}
+	if err := validateFolder(req.Folder); err != nil {
+		return err
+	}
+
+	req.ContentHash = strings.ToLower(req.ContentHash)
+	if req.ContentHash != "" {
+		if existing := models.FindDuplicateAsset(config.DB, req.ContentHash, req.MediaType); existing != nil {
+			return c.JSON(fiber.Map{
+				"data": fiber.Map{
+					"duplicate": true,
+					"asset":     existing,
+				},
+			})
+		}
+	}
+
+	maxSize := int64(config.AppConfig.PresignedUploadMaxSize)
+	if req.Size > maxSize {
+		return apierror.New(fiber.StatusRequestEntityTooLarge, apierror.CodeFileTooLarge, fmt.Sprintf("File size exceeds maximum limit of %d MB", maxSize/(1024*1024)))
+	}
+	if err := utils.Storage.CheckStorageLimit(req.Size); err != nil {
+		return apierror.New(fiber.StatusRequestEntityTooLarge, apierror.CodeStorageLimitExceeded, err.Error())
+	}
+
+	// The file lands in a staging key and is processed into the folder on completion
+	expires := time.Duration(config.AppConfig.PresignedUploadExpiryMinutes) * time.Minute
+	key := fmt.Sprintf("incoming/%s%s", uuid.New().String(), strings.ToLower(filepath.Ext(req.Filename)))
+	presigned, err := utils.Storage.PresignUpload(key, req.ContentType, req.Size, expires)
+	if errors.Is(err, utils.ErrPresignNotSupported) {
+		return apierror.New(fiber.StatusNotImplemented, apierror.CodeStorageUnavailable, "Presigned uploads need the R2 storage backend, use /content/upload instead")
+	}
+	if err != nil {
+		return apierror.Internal("Failed to presign upload: " + err.Error())
+	}
+
+	upload := models.MediaUpload{
+		Key:         key,
+		Folder:      req.Folder,
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		MediaType:   req.MediaType,
+		ContentHash: req.ContentHash,
+		AltText:     req.AltText,
+		AltTextID:   req.AltTextID,
+		ExpiresAt:   presigned.ExpiresAt,
+	}
+	if user, ok := c.Locals("user").(models.User); ok {
+		upload.UploadedByID = &user.ID
+		upload.UploadedBy = user.Username
+	}
+	if err := config.DB.Create(&upload).Error; err != nil {
+		return apierror.Internal("Failed to create upload")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": fiber.Map{
+			"duplicate":  false,
+			"upload":     upload,
+			"upload_url": presigned,
+		},
+	})
+}
+
+// CompleteMediaUpload checks the file uploaded to a slot, then generates its thumbnail and
+// variants and records it in the media library, like UploadContent does. A file that was
+// uploaded before is discarded in favour of the stored one unless ?force=true is given.
+// Slots past their expiry are rejected with 410 and their staged file is deleted.
+func CompleteMediaUpload(c *fiber.Ctx) error {
+	if err := requireStorage(); err != nil {
+		return err
+	}
+
+	var upload models.MediaUpload
+	if err := config.DB.Where("id = ?", c.Params("id")).First(&upload).Error; err != nil {
+		return apierror.NotFound("Upload not found")
+	}
+	if upload.CompletedAt != nil {
+		return apierror.Conflict("Upload has already been completed")
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		// The presigned URL is no longer valid, so the slot can't be completed; start a new upload
+		discardStagedUpload(upload.Key)
+		return apierror.New(fiber.StatusGone, apierror.CodeUploadExpired, "Upload has expired; request a new upload URL")
+	}
+
+	// Claim the upload so concurrent requests can't complete it twice. The claim is released
+	// when completing fails, so the client can upload the file again and retry.
+	claim := config.DB.Model(&models.MediaUpload{}).
+		Where("id = ? AND completed_at IS NULL", upload.ID).
+		Update("completed_at", time.Now())
+	if claim.Error != nil {
+		return apierror.Internal("Failed to claim upload")
+	}
+	if claim.RowsAffected == 0 {
+		return apierror.Conflict("Upload has already been completed")
+	}
+	completed := false
+	defer func() {
+		if !completed {
+			releaseUploadClaim(upload)
+		}
+	}()
+
+	object, err := utils.Storage.StatObject(upload.Key)
+	if errors.Is(err, utils.ErrObjectNotFound) {
+		return apierror.BadRequest("The file has not been uploaded yet")
+	}
+	if err != nil {
+		return apierror.Internal("Failed to check uploaded file: " + err.Error())
+	}
+	if object.Size != upload.Size || (object.ContentType != "" && object.ContentType != upload.ContentType) {
+		discardStagedUpload(upload.Key)
+		return apierror.BadRequest(fmt.Sprintf("Uploaded file does not match the upload: expected %d bytes of %s", upload.Size, upload.ContentType))
+	}
+
+	content, err := utils.Storage.GetObject(upload.Key)
+	if err != nil {
+		return apierror.Internal("Failed to read uploaded file: " + err.Error())
+	}
+
+	contentHash := utils.ContentHash(content)
+	if upload.ContentHash != "" && upload.ContentHash != contentHash {
+		discardStagedUpload(upload.Key)
+		return apierror.BadRequest("Uploaded file does not match the declared content_hash")
+	}
+	if c.Query("force") != "true" {
+		if existing := models.FindDuplicateAsset(config.DB, contentHash, upload.MediaType); existing != nil {
+			discardStagedUpload(upload.Key)
+			markUploadCompleted(upload, existing)
+			completed = true
+			return c.JSON(UploadContentResponse{UploadResponse: existing.UploadResponse(), Asset: existing, Duplicate: true})
+		}
+	}
+
+	// The limit may have been reached since the upload URL was handed out
+	if err := utils.Storage.CheckStorageLimit(upload.Size); err != nil {
+		return apierror.New(fiber.StatusRequestEntityTooLarge, apierror.CodeStorageLimitExceeded, err.Error())
+	}
 
-	// Upload to R2 with thumbnail generation and dimension detection
-	uploadResponse, err := utils.Storage.UploadImageWithThumbnail(file, folder)
+	uploadResponse, err := utils.Storage.UploadMediaContent(content, upload.Filename, upload.Folder, upload.MediaType)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to upload file: " + err.Error(),
-			"code":    "INTERNAL_ERROR",
-		})
+		if isRejectedFile(err) {
+			// The file itself was rejected and can't be completed with a retry
+			discardStagedUpload(upload.Key)
+		}
+		return uploadError(err)
+	}
+	discardStagedUpload(upload.Key)
+
+	asset := recordMediaAsset(c, uploadResponse, upload.Folder, upload.Filename, upload.AltText, upload.AltTextID)
+
+	markUploadCompleted(upload, asset)
+	completed = true
+
+	return c.JSON(UploadContentResponse{UploadResponse: uploadResponse, Asset: asset})
+}
+
+// =============================================================================
+// UPLOAD HELPERS
+// =============================================================================
+
+// validateMediaType checks the media_type of an upload; empty means detect it from the file
+func validateMediaType(mediaType string) error {
+	switch mediaType {
+	case "", utils.MediaTypeImage, utils.MediaTypeVideo, utils.MediaTypePanorama:
+		return nil
 	}
+	return apierror.Validation([]apierror.FieldError{{Field: "media_type", Message: "must be one of image, video or panorama"}})
+}
 
-	return c.JSON(uploadResponse)
+// validateFolder checks the folder form or JSON field of an upload
+func validateFolder(folder string) error {
+	if err := utils.ValidateFolder(folder); err != nil {
+		return apierror.Validation([]apierror.FieldError{{Field: "folder", Message: err.Error()}})
+	}
+	return nil
+}
+
+// uploadError converts an error from processing an upload into an API error: files of a type
+// that isn't allowed or whose content doesn't match the name are 415, images with too many
+// pixels 413
+func uploadError(err error) error {
+	switch {
+	case errors.Is(err, utils.ErrFileTypeNotAllowed):
+		return apierror.New(fiber.StatusUnsupportedMediaType, apierror.CodeUnsupportedFileType, err.Error())
+	case errors.Is(err, utils.ErrImageTooLarge):
+		return apierror.New(fiber.StatusRequestEntityTooLarge, apierror.CodeFileTooLarge, err.Error())
+	}
+	return apierror.Internal("Failed to upload file: " + err.Error())
+}
+
+// isRejectedFile reports whether an upload failed because of the file rather than on our side
+func isRejectedFile(err error) bool {
+	return errors.Is(err, utils.ErrFileTypeNotAllowed) || errors.Is(err, utils.ErrImageTooLarge)
+}
+
+// recordMediaAsset keeps a media library record of an upload. It returns nil when the record
+// can't be saved; the file is stored already, so that doesn't fail the upload. The poster frame
+// of a video is recorded as an image of its own.
+func recordMediaAsset(c *fiber.Ctx, uploadResponse *utils.UploadResponse, folder, filename, altText, altTextID string) *models.MediaAsset {
+	var posterURL string
+	if poster := uploadResponse.Poster; poster != nil {
+		posterFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + " (poster)"
+		recordMediaAsset(c, poster, folder, posterFilename, altText, altTextID)
+		posterURL = poster.FileURL
+	}
+
+	asset := models.MediaAsset{
+		Key:          uploadResponse.Key,
+		URL:          uploadResponse.FileURL,
+		ThumbnailKey: uploadResponse.ThumbnailKey,
+		ThumbnailURL: uploadResponse.ThumbnailURL,
+		Folder:       folder,
+		Filename:     filename,
+		ContentType:  uploadResponse.ContentType,
+		Size:         uploadResponse.FileSize,
+		AltText:      altText,
+		AltTextID:    altTextID,
+		MediaType:    uploadResponse.MediaType,
+		Duration:     uploadResponse.DurationSeconds,
+		PosterURL:    posterURL,
+		ContentHash:  uploadResponse.ContentHash,
+	}
+	if uploadResponse.Dimensions != nil {
+		asset.Width = uploadResponse.Dimensions.Width
+		asset.Height = uploadResponse.Dimensions.Height
+	}
+	if len(uploadResponse.Variants) > 0 {
+		variants, _ := json.Marshal(uploadResponse.Variants)
+		asset.Variants = datatypes.JSON(variants)
+		asset.SrcSet = uploadResponse.SrcSet
+	}
+	if exifData := uploadResponse.Exif; exifData != nil {
+		asset.CapturedAt = exifData.CapturedAt
+		asset.CameraMake = exifData.CameraMake
+		asset.CameraModel = exifData.CameraModel
+		asset.Artist = exifData.Artist
+		asset.Latitude = exifData.Latitude
+		asset.Longitude = exifData.Longitude
+	}
+	if placeholder := uploadResponse.ImagePlaceholder; placeholder != nil {
+		asset.BlurHash = placeholder.BlurHash
+		asset.LQIP = placeholder.LQIP
+		asset.DominantColor = placeholder.DominantColor
+	}
+	if user, ok := c.Locals("user").(models.User); ok {
+		asset.UploadedByID = &user.ID
+		asset.UploadedBy = user.Username
+	}
+	if err := config.DB.Create(&asset).Error; err != nil {
+		fmt.Printf("Warning: Failed to record media asset %s: %v\n", asset.Key, err)
+		return nil
+	}
+	return &asset
+}
+
+// markUploadCompleted closes an upload slot; asset is the media asset it became, if recorded
+func markUploadCompleted(upload models.MediaUpload, asset *models.MediaAsset) {
+	updates := map[string]interface{}{"completed_at": time.Now()}
+	if asset != nil {
+		updates["asset_id"] = asset.ID
+	}
+	if err := config.DB.Model(&upload).Updates(updates).Error; err != nil {
+		fmt.Printf("Warning: Failed to mark upload %d as completed: %v\n", upload.ID, err)
+	}
+}
+
+// releaseUploadClaim makes an upload that failed to complete available to complete again
+func releaseUploadClaim(upload models.MediaUpload) {
+	if err := config.DB.Model(&upload).Update("completed_at", nil).Error; err != nil {
+		fmt.Printf("Warning: Failed to release upload %d: %v\n", upload.ID, err)
+	}
+}
+
+// discardStagedUpload deletes a presigned upload's staging object; the media GC removes
+// it later if this fails
+func discardStagedUpload(key string) {
+	if err := utils.Storage.DeleteObject(key); err != nil {
+		fmt.Printf("Warning: Failed to delete staged upload %s: %v\n", key, err)
+	}
 }
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"yaro-wora-be/apierror"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// =============================================================================
+// VERSION HELPERS
+// =============================================================================
+
+// setETag exposes the version of a row as its ETag so editors can send it back in If-Match
+func setETag(c *fiber.Ctx, version uint) {
+	c.Set(fiber.HeaderETag, fmt.Sprintf("%q", strconv.FormatUint(uint64(version), 10)))
+}
+
+// ifMatchVersion reads the version the client based its changes on.
+// Accepts the ETag as sent ("3" or W/"3") as well as the bare version (3).
+func ifMatchVersion(c *fiber.Ctx) (uint, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return 0, apierror.PreconditionRequired("If-Match header with the version being edited is required")
+	}
+
+	value := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || version == 0 {
+		return 0, apierror.BadRequest("Invalid If-Match header")
+	}
+	return uint(version), nil
+}
+
+// nextVersion locks the row for the rest of the transaction, checks the If-Match header
+// against its stored version and returns the version the row gets when saved.
+// A stale version is rejected with 409 and the current version so the editor can reload.
+func nextVersion(tx *gorm.DB, c *fiber.Ctx, model interface{}, id uint) (uint, error) {
+	expected, err := ifMatchVersion(c)
+	if err != nil {
+		return 0, err
+	}
+
+	var current uint
+	if err := tx.Model(model).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("version").
+		Where("id = ?", id).
+		Scan(&current).Error; err != nil {
+		return 0, err
+	}
+
+	if current != expected {
+		setETag(c, current)
+		return 0, apierror.New(fiber.StatusConflict, apierror.CodeVersionConflict,
+			"The content was changed by someone else; reload it and apply your changes again").
+			With("current_version", current)
+	}
+	return current + 1, nil
+}
+
+// modelVersion reads the version of a model embedding BaseModel
+func modelVersion(model interface{}) uint {
+	return uint(reflect.ValueOf(model).Elem().FieldByName("Version").Uint())
+}
+
+// setModelVersion sets the version of a model embedding BaseModel
+func setModelVersion(model interface{}, version uint) {
+	reflect.ValueOf(model).Elem().FieldByName("Version").SetUint(uint64(version))
+}
@@ -1,12 +1,34 @@
 package handlers
 
 import (
+	"time"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// WhyVisitRequest is the body of POST /why-visit and PUT /why-visit/:id
+type WhyVisitRequest struct {
+	models.Publication
+	Title         string `json:"title" validate:"required"`
+	TitleID       string `json:"title_id" validate:"required"`
+	Description   string `json:"description"`
+	DescriptionID string `json:"description_id"`
+	IconURL       string `json:"icon_url" validate:"required,link"`
+}
+
+// GeneralWhyVisitContentRequest is the body of PUT /why-visit-content
+type GeneralWhyVisitContentRequest struct {
+	WhyVisitSectionTitlePart1    string `json:"why_visit_section_title_part_1"`
+	WhyVisitSectionTitlePart1ID  string `json:"why_visit_section_title_part_1_id"`
+	WhyVisitSectionTitlePart2    string `json:"why_visit_section_title_part_2"`
+	WhyVisitSectionTitlePart2ID  string `json:"why_visit_section_title_part_2_id"`
+	WhyVisitSectionDescription   string `json:"why_visit_section_description"`
+	WhyVisitSectionDescriptionID string `json:"why_visit_section_description_id"`
+}
+
 // =============================================================================
 // WHY VISIT MANAGEMENT - ADMIN
 // =============================================================================
@@ -14,20 +36,17 @@ import (
 // CreateWhyVisit creates a new why visit item
 func CreateWhyVisit(c *fiber.Ctx) error {
 	var whyVisit models.WhyVisit
-	if err := c.BodyParser(&whyVisit); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	var req WhyVisitRequest
+	if err := bindRequest(c, &req, &whyVisit); err != nil {
+		return err
+	}
+
+	if err := whyVisit.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
 	}
 
 	if err := config.DB.Create(&whyVisit).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to create why visit item",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to create why visit item")
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(whyVisit)
@@ -39,27 +58,22 @@ func UpdateWhyVisit(c *fiber.Ctx) error {
 
 	var whyVisit models.WhyVisit
 	if err := config.DB.Where("id = ?", id).First(&whyVisit).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error":   true,
-			"message": "Why visit item not found",
-			"code":    "NOT_FOUND",
-		})
+		return apierror.NotFound("Why visit item not found")
 	}
 
-	if err := c.BodyParser(&whyVisit); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	before := revisionSnapshot(whyVisit)
+
+	var req WhyVisitRequest
+	if err := bindRequest(c, &req, &whyVisit); err != nil {
+		return err
 	}
 
-	if err := config.DB.Save(&whyVisit).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update why visit item",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := whyVisit.Publication.Normalize(time.Now()); err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+
+	if err := saveWithRevision(c, "why-visit", whyVisit.ID, before, &whyVisit); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update why visit item"))
 	}
 
 	return c.JSON(whyVisit)
@@ -70,11 +84,7 @@ func DeleteWhyVisit(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	if err := config.DB.Delete(&models.WhyVisit{}, id).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to delete why visit item",
-			"code":    "INTERNAL_ERROR",
-		})
+		return apierror.Internal("Failed to delete why visit item")
 	}
 
 	return c.JSON(fiber.Map{
@@ -85,37 +95,27 @@ func DeleteWhyVisit(c *fiber.Ctx) error {
 
 // UpdateGeneralWhyVisitContent updates the general why visit content (singleton)
 func UpdateGeneralWhyVisitContent(c *fiber.Ctx) error {
+	// Try to find existing content, create if not exists
 	var content models.GeneralWhyVisitContent
-	if err := c.BodyParser(&content); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   true,
-			"message": "Invalid request body",
-			"code":    "BAD_REQUEST",
-		})
+	exists := config.DB.First(&content).Error == nil
+	before := revisionSnapshot(content)
+
+	var req GeneralWhyVisitContentRequest
+	if err := bindRequest(c, &req, &content); err != nil {
+		return err
 	}
 
-	// Try to find existing content, create if not exists
-	var existingContent models.GeneralWhyVisitContent
-	if err := config.DB.First(&existingContent).Error; err != nil {
+	if !exists {
 		// Create new content
 		if err := config.DB.Create(&content).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":   true,
-				"message": "Failed to create general why visit content",
-				"code":    "INTERNAL_ERROR",
-			})
+			return apierror.Internal("Failed to create general why visit content")
 		}
 		return c.Status(fiber.StatusCreated).JSON(content)
 	}
 
 	// Update existing content
-	content.ID = existingContent.ID
-	if err := config.DB.Save(&content).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to update general why visit content",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := saveWithRevision(c, "why-visit-content", content.ID, before, &content); err != nil {
+		return apierror.Or(err, apierror.Internal("Failed to update general why visit content"))
 	}
 
 	return c.JSON(content)
@@ -129,12 +129,8 @@ func UpdateGeneralWhyVisitContent(c *fiber.Ctx) error {
 func GetWhyVisit(c *fiber.Ctx) error {
 	var whyVisit []models.WhyVisit
 
-	if err := config.DB.Order("created_at ASC").Find(&whyVisit).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   true,
-			"message": "Failed to fetch why visit data",
-			"code":    "INTERNAL_ERROR",
-		})
+	if err := config.DB.Scopes(publicationScope(c)).Order("created_at ASC").Find(&whyVisit).Error; err != nil {
+		return apierror.Internal("Failed to fetch why visit data")
 	}
 
 	return c.JSON(fiber.Map{
@@ -154,6 +150,7 @@ func GetGeneralWhyVisitContent(c *fiber.Ctx) error {
 			"data": models.GeneralWhyVisitContent{},
 		})
 	}
+	setETag(c, content.Version)
 	return c.JSON(fiber.Map{
 		"data": content,
 	})
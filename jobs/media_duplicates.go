@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strings"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+)
+
+// DuplicateObject is one copy of a file that is stored more than once
+type DuplicateObject struct {
+	Key     string `json:"key"`
+	URL     string `json:"url"`
+	AssetID *uint  `json:"asset_id,omitempty"` // Media library record, if any
+	Usages  int    `json:"usages"`             // Places in the content that use this copy
+}
+
+// DuplicateGroup is a set of objects with identical content
+type DuplicateGroup struct {
+	ContentHash string            `json:"content_hash"`
+	Size        int64             `json:"size"`
+	Objects     []DuplicateObject `json:"objects"`
+	WastedBytes int64             `json:"wasted_bytes"` // Size of every copy but one
+}
+
+// DuplicateMediaReport lists the files stored more than once in the bucket
+type DuplicateMediaReport struct {
+	ScannedObjects int              `json:"scanned_objects"`
+	HashedObjects  int              `json:"hashed_objects"`
+	Groups         []DuplicateGroup `json:"groups"`
+	WastedBytes    int64            `json:"wasted_bytes"`
+}
+
+// FindDuplicateMedia finds uploads that are stored more than once, e.g. from before uploads were
+// deduplicated or forced re-uploads. Thumbnails and variants follow their original and are left
+// out. Only objects that share their size with another object are downloaded and hashed.
+func FindDuplicateMedia() (*DuplicateMediaReport, error) {
+	if utils.Storage == nil {
+		return nil, errors.New("storage is not initialized")
+	}
+
+	objects, err := utils.Storage.ListObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	bySize := map[int64][]utils.StorageObject{}
+	for _, object := range objects {
+		if isDerivedObject(object.Key) || strings.HasPrefix(object.Key, "incoming/") {
+			continue
+		}
+		bySize[object.Size] = append(bySize[object.Size], object)
+	}
+
+	report := &DuplicateMediaReport{ScannedObjects: len(objects), Groups: []DuplicateGroup{}}
+	byHash := map[string][]utils.StorageObject{}
+	for _, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		for _, object := range candidates {
+			content, err := utils.Storage.GetObject(object.Key)
+			if err != nil {
+				return nil, err
+			}
+			report.HashedObjects++
+			hash := utils.ContentHash(content)
+			byHash[hash] = append(byHash[hash], object)
+		}
+	}
+
+	usageIndex, err := models.BuildMediaUsageIndex(config.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	for hash, copies := range byHash {
+		if len(copies) < 2 {
+			continue
+		}
+
+		keys := make([]string, len(copies))
+		for i, object := range copies {
+			keys[i] = object.Key
+		}
+		var assets []models.MediaAsset
+		if err := config.DB.Where("key IN ?", keys).Find(&assets).Error; err != nil {
+			return nil, err
+		}
+		assetsByKey := map[string]models.MediaAsset{}
+		for _, asset := range assets {
+			assetsByKey[asset.Key] = asset
+		}
+
+		group := DuplicateGroup{ContentHash: hash, Size: copies[0].Size}
+		for _, object := range copies {
+			url := utils.Storage.GenerateImageURL(object.Key)
+			duplicate := DuplicateObject{Key: object.Key, URL: url, Usages: len(usageIndex[url])}
+			if asset, ok := assetsByKey[object.Key]; ok {
+				duplicate.AssetID = &asset.ID
+				duplicate.Usages = len(usageIndex.Usages(asset))
+			}
+			group.Objects = append(group.Objects, duplicate)
+		}
+		sort.Slice(group.Objects, func(i, j int) bool { return group.Objects[i].Key < group.Objects[j].Key })
+		group.WastedBytes = group.Size * int64(len(copies)-1)
+
+		report.Groups = append(report.Groups, group)
+		report.WastedBytes += group.WastedBytes
+	}
+
+	// Biggest savings first
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].WastedBytes != report.Groups[j].WastedBytes {
+			return report.Groups[i].WastedBytes > report.Groups[j].WastedBytes
+		}
+		return report.Groups[i].ContentHash < report.Groups[j].ContentHash
+	})
+
+	return report, nil
+}
+
+// isDerivedObject reports whether a key is a thumbnail or variant generated from an upload
+func isDerivedObject(key string) bool {
+	return objectStem(key) != strings.TrimSuffix(key, path.Ext(key))
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+)
+
+// OrphanedObject is an R2 object that no content references
+type OrphanedObject struct {
+	Key          string    `json:"key"`
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// MediaGCReport describes the result of an orphaned media collection
+type MediaGCReport struct {
+	DryRun            bool             `json:"dry_run"`
+	GraceHours        int              `json:"grace_hours"`
+	ScannedObjects    int              `json:"scanned_objects"`
+	ReferencedObjects int              `json:"referenced_objects"`
+	RecentObjects     int              `json:"recent_objects"` // Unreferenced but within the grace period
+	Orphans           []OrphanedObject `json:"orphans"`
+	OrphanedBytes     int64            `json:"orphaned_bytes"`
+	Deleted           int              `json:"deleted"`
+	Failed            int              `json:"failed"`
+}
+
+// RunMediaGC is the scheduled orphaned media collection. It only reports
+// unless MEDIA_GC_DELETE is enabled.
+func RunMediaGC() error {
+	report, err := CollectOrphanedMedia(!config.AppConfig.MediaGCDelete)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Orphans) == 0 {
+		return nil
+	}
+	if report.DryRun {
+		log.Printf("🧹 Media GC: %d orphaned object(s) (%.2f MB) found; set MEDIA_GC_DELETE=true to delete them",
+			len(report.Orphans), float64(report.OrphanedBytes)/(1024*1024))
+	} else {
+		log.Printf("🧹 Media GC: %d orphaned object(s) deleted (%.2f MB), %d failed",
+			report.Deleted, float64(report.OrphanedBytes)/(1024*1024), report.Failed)
+	}
+	return nil
+}
+
+// CollectOrphanedMedia lists the bucket and finds objects that no content references, including
+// images nested in JSON sections, images linked from markdown and text, and content in the trash. Objects younger than the grace period
+// are kept because they may belong to an upload whose content hasn't been saved yet.
+// Unless dryRun is set, orphans are deleted from R2 together with their media library records.
+func CollectOrphanedMedia(dryRun bool) (*MediaGCReport, error) {
+	if utils.Storage == nil {
+		return nil, errors.New("storage is not initialized")
+	}
+	if config.AppConfig.StorageBackend == utils.StorageBackendR2 && config.AppConfig.R2PublicURL == "" {
+		// Without the public URL no reference can be matched to a key and everything would look orphaned
+		return nil, errors.New("R2_PUBLIC_URL is not configured")
+	}
+
+	objects, err := utils.Storage.ListObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	usageIndex, err := models.BuildMediaUsageIndex(config.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[string]bool{}
+	for imageURL := range usageIndex {
+		if key := objectKey(imageURL); key != "" {
+			referenced[objectStem(key)] = true
+		}
+	}
+
+	report := &MediaGCReport{
+		DryRun:         dryRun,
+		GraceHours:     config.AppConfig.MediaGCGraceHours,
+		ScannedObjects: len(objects),
+		Orphans:        []OrphanedObject{},
+	}
+	cutoff := time.Now().Add(-time.Duration(config.AppConfig.MediaGCGraceHours) * time.Hour)
+
+	for _, object := range objects {
+		switch {
+		case referenced[objectStem(object.Key)]:
+			report.ReferencedObjects++
+		case object.LastModified.After(cutoff):
+			report.RecentObjects++
+		default:
+			report.Orphans = append(report.Orphans, OrphanedObject{
+				Key:          object.Key,
+				URL:          utils.Storage.GenerateImageURL(object.Key),
+				Size:         object.Size,
+				LastModified: object.LastModified,
+			})
+			report.OrphanedBytes += object.Size
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	for _, orphan := range report.Orphans {
+		if err := utils.Storage.DeleteObject(orphan.Key); err != nil {
+			log.Printf("❌ Media GC: failed to delete %s: %v", orphan.Key, err)
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+
+		if err := config.DB.Unscoped().Where("key = ?", orphan.Key).Delete(&models.MediaAsset{}).Error; err != nil {
+			log.Printf("❌ Media GC: failed to remove media record for %s: %v", orphan.Key, err)
+		}
+	}
+
+	return report, nil
+}
+
+// objectKey returns the key of an image URL. URLs on another host (e.g. a custom domain in front
+// of the bucket) fall back to their path so the objects they point at are not collected.
+func objectKey(imageURL string) string {
+	if key := utils.Storage.KeyFromURL(imageURL); key != "" {
+		return key
+	}
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Path, "/")
+}
+
+// derivedObjectPattern matches the suffix of thumbnails and responsive variants after the unique ID of an upload
+var derivedObjectPattern = regexp.MustCompile(`(_[0-9a-f]{8})(_thumb|@[a-z0-9-]+)$`)
+
+// objectStem identifies an image, its thumbnail and its variants by the same name: "news/photo_ab12cd34.webp",
+// "news/photo_ab12cd34_thumb.webp" and "news/photo_ab12cd34@md.avif" are all "news/photo_ab12cd34"
+func objectStem(key string) string {
+	stem := strings.TrimSuffix(key, path.Ext(key))
+	if derivedObjectPattern.MatchString(stem) {
+		return derivedObjectPattern.ReplaceAllString(stem, "$1")
+	}
+	return strings.TrimSuffix(stem, "_thumb")
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import "testing"
+
+func TestObjectStem(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"news/photo_ab12cd34.webp", "news/photo_ab12cd34"},
+		{"news/photo_ab12cd34_thumb.webp", "news/photo_ab12cd34"},
+		{"news/photo_ab12cd34@md.webp", "news/photo_ab12cd34"},
+		{"news/photo_ab12cd34@card.avif", "news/photo_ab12cd34"},
+		{"news/photo_ab12cd34@x-large.webp", "news/photo_ab12cd34"},
+		{"legacy/photo_thumb.jpg", "legacy/photo"},
+		{"legacy/photo.jpg", "legacy/photo"},
+		{"news/my@home_ab12cd34.webp", "news/my@home_ab12cd34"},
+		{"noext", "noext"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := objectStem(tt.key); got != tt.want {
+				t.Errorf("objectStem(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
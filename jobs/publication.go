@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"log"
+	"time"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+)
+
+// ApplyScheduledPublications publishes scheduled content that is due and archives expired content
+func ApplyScheduledPublications() error {
+	published, archived, err := models.ApplyScheduledPublications(config.DB, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if published > 0 || archived > 0 {
+		log.Printf("📰 Publication scheduler: %d item(s) published, %d item(s) archived", published, archived)
+	}
+	return nil
+}
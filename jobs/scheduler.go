@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"log"
+	"time"
+	"yaro-wora-be/config"
+)
+
+// startupStagger spaces out the first runs of the jobs that go through the whole bucket, so a
+// restart doesn't list it several times at once
+const startupStagger = time.Minute
+
+// Start launches all background jobs
+func Start() {
+	runEvery("publication scheduler", time.Duration(config.AppConfig.PublishSchedulerIntervalSeconds)*time.Second, 0, ApplyScheduledPublications)
+
+	// The usage reconcile goes first; it also seeds the totals the dashboard reads
+	runEvery("storage usage", time.Duration(config.AppConfig.StorageUsageIntervalMinutes)*time.Minute, 0, ReconcileStorageUsage)
+
+	trashInterval := time.Duration(config.AppConfig.TrashPurgeIntervalMinutes) * time.Minute
+	if config.AppConfig.TrashRetentionDays <= 0 {
+		trashInterval = 0
+	}
+	runEvery("trash purge", trashInterval, startupStagger, PurgeExpiredTrash)
+
+	runEvery("media gc", time.Duration(config.AppConfig.MediaGCIntervalMinutes)*time.Minute, 2*startupStagger, RunMediaGC)
+}
+
+// runEvery runs job after delay and then on every tick of interval in a background goroutine.
+// A non-positive interval disables the job.
+func runEvery(name string, interval, delay time.Duration, job func() error) {
+	if interval <= 0 {
+		log.Printf("⏸️  Background job %q disabled", name)
+		return
+	}
+
+	go func() {
+		run := func() {
+			// Never let a failing job take the server down
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("❌ Background job %q panicked: %v", name, r)
+				}
+			}()
+			if err := job(); err != nil {
+				log.Printf("❌ Background job %q failed: %v", name, err)
+			}
+		}
+
+		time.Sleep(delay)
+		run()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+
+	log.Printf("⏱️  Background job %q scheduled every %s", name, interval)
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"log"
+	"time"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+	"yaro-wora-be/utils"
+)
+
+// ReconcileStorageUsage lists the bucket to correct the running usage totals for changes made
+// outside the API, and records the result as today's usage snapshot
+func ReconcileStorageUsage() error {
+	if utils.Storage == nil {
+		return nil
+	}
+
+	usage, err := utils.Storage.ReconcileUsage()
+	if err != nil {
+		return err
+	}
+	if usage.Drift != 0 {
+		log.Printf("📦 Storage usage: corrected by %+.2f MB to %.2f MB", float64(usage.Drift)/(1024*1024), float64(usage.TotalBytes)/(1024*1024))
+	}
+
+	return models.RecordStorageUsage(config.DB, usage, time.Now())
+}
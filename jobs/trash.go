@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"errors"
+	"log"
+	"time"
+	"yaro-wora-be/config"
+	"yaro-wora-be/models"
+)
+
+// PurgeExpiredTrash permanently deletes content that has been in the trash longer than the retention period
+func PurgeExpiredTrash() error {
+	retention := config.AppConfig.TrashRetentionDays
+	if retention <= 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -retention)
+
+	purged := 0
+	for _, ct := range models.ContentTypes() {
+		if ct.Singleton {
+			continue
+		}
+
+		ids, err := models.ExpiredTrashIDs(config.DB, ct, cutoff)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			row, err := models.PurgeTrashed(config.DB, ct, id)
+			if err != nil {
+				// Referenced rows stay in the trash until their dependents are gone
+				if !errors.Is(err, models.ErrTrashItemInUse) {
+					log.Printf("❌ Failed to purge %s #%d: %v", ct.Key, id, err)
+				}
+				continue
+			}
+
+			models.DeleteUnreferencedImages(config.DB, row)
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		log.Printf("🗑️  Trash purge: %d item(s) permanently deleted", purged)
+	}
+	return nil
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"log"
-	"time"
+	"os"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
+	"yaro-wora-be/jobs"
 	"yaro-wora-be/migrations"
 	"yaro-wora-be/models"
 	"yaro-wora-be/routes"
@@ -13,6 +15,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 func main() {
@@ -22,20 +25,29 @@ func main() {
 	// Connect to database
 	config.ConnectDatabase()
 
+	// Initialize storage. Before migrations, which index the images used by the content
+	// and need the storage URL to find them in text.
+	if err := utils.InitStorage(); err != nil {
+		log.Printf("Warning: Failed to initialize storage: %v", err)
+		log.Println("Some upload features may not work properly")
+	}
+
 	// Run migrations
 	models.AutoMigrate()
 
+	// CLI subcommands (export, import) run against the database and exit
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	// Seed initial data
 	// if config.AppConfig.AppEnv == "development" {
 	// Import migrations package
 	migrations.SeedData()
 	// }
 
-	// Initialize storage
-	if err := utils.InitStorage(); err != nil {
-		log.Printf("Warning: Failed to initialize storage: %v", err)
-		log.Println("Some upload features may not work properly")
-	}
+	// Start background jobs (scheduled publishing, ...)
+	jobs.Start()
 
 	// Create Fiber app
 	log.Printf("📦 Max file upload size configured: %d bytes (%.2f MB)",
@@ -45,27 +57,26 @@ func main() {
 	app := fiber.New(fiber.Config{
 		BodyLimit: config.AppConfig.MaxFileUploadSize, // Set max body size from config (default 20MB)
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
+			// Every error leaves the API in the same shape: {"error", "code", "message", "fields", "request_id"}
+			apiErr := apierror.From(err)
+			if apiErr.Status >= fiber.StatusInternalServerError {
+				log.Printf("❌ [%v] %s %s: %v", c.Locals("requestid"), c.Method(), c.Path(), err)
 			}
-
-			return c.Status(code).JSON(fiber.Map{
-				"error":     true,
-				"message":   err.Error(),
-				"code":      "FIBER_ERROR",
-				"timestamp": time.Now().Format(time.RFC3339),
-			})
+			return apierror.Render(c, apiErr)
 		},
 	})
 
 	// Global middleware
 	app.Use(recover.New())
-	app.Use(logger.New())
+	app.Use(requestid.New())
+	app.Use(logger.New(logger.Config{
+		Format: "[${time}] ${locals:requestid} ${status} - ${latency} ${method} ${path}\n",
+	}))
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization,X-Session-ID",
+		AllowOrigins:  "*",
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:  "Origin,Content-Type,Accept,Authorization,X-Session-ID,X-Request-ID,If-Match",
+		ExposeHeaders: "X-Request-ID,ETag",
 	}))
 
 	// Health check endpoint
@@ -77,6 +88,14 @@ func main() {
 		})
 	})
 
+	// Serve uploaded files from disk when using the local storage backend
+	if config.AppConfig.StorageBackend == utils.StorageBackendLocal {
+		app.Static(utils.LocalStoragePath(config.AppConfig.LocalStoragePublicURL), config.AppConfig.LocalStorageDir, fiber.Static{
+			ByteRange: true,
+			MaxAge:    86400,
+		})
+	}
+
 	// Setup all routes
 	routes.SetupRoutes(app)
 
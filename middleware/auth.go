@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"strings"
+	"yaro-wora-be/apierror"
 	"yaro-wora-be/config"
 	"yaro-wora-be/models"
 	"yaro-wora-be/utils"
@@ -15,21 +16,13 @@ func AdminAuth() fiber.Handler {
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Authorization header is required",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Authorization header is required")
 		}
 
 		// Check if it's Bearer token
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || tokenParts[0] != "Bearer" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid authorization header format",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Invalid authorization header format")
 		}
 
 		token := tokenParts[1]
@@ -37,21 +30,13 @@ func AdminAuth() fiber.Handler {
 		// Validate JWT token
 		claims, err := utils.ValidateJWT(token)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid or expired token",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Invalid or expired token")
 		}
 
 		// Verify user exists and is active
 		var user models.User
 		if err := config.DB.Where("id = ? AND is_active = ?", claims.UserID, true).First(&user).Error; err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "User not found or inactive",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("User not found or inactive")
 		}
 
 		// Set user data in context
@@ -69,11 +54,7 @@ func SuperAdminOnly() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		role := c.Locals("role").(string)
 		if role != "super_admin" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-				"error":   true,
-				"message": "Super admin access required",
-				"code":    "FORBIDDEN",
-			})
+			return apierror.Forbidden("Super admin access required")
 		}
 		return c.Next()
 	}
@@ -85,20 +66,12 @@ func BasicAuth() fiber.Handler {
 		// Get Authorization header
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Authorization required",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Authorization required")
 		}
 
 		// Check if it's Basic auth
 		if !strings.HasPrefix(authHeader, "Basic ") {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Basic authentication required",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Basic authentication required")
 		}
 
 		// For now, we'll use simple hardcoded credentials from config
@@ -109,20 +82,12 @@ func BasicAuth() fiber.Handler {
 		// Parse basic auth header manually
 		authValue := strings.TrimPrefix(authHeader, "Basic ")
 		if authValue == authHeader {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid authorization header format",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Invalid authorization header format")
 		}
 
 		// For now, simple check without base64 decoding (you might want to implement proper basic auth parsing)
 		if authValue != "YWRtaW46YWRtaW4xMjM=" { // base64 for admin:admin123
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error":   true,
-				"message": "Invalid credentials",
-				"code":    "UNAUTHORIZED",
-			})
+			return apierror.Unauthorized("Invalid credentials")
 		}
 
 		// Set basic auth user in context
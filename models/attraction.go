@@ -4,6 +4,7 @@ import "gorm.io/datatypes"
 
 type Attraction struct {
 	BaseModel
+	Publication
 	Title         string         `json:"title" gorm:"type:citext;not null"`
 	TitleID       string         `json:"title_id" gorm:"type:citext;not null"`
 	Subtitle      string         `json:"subtitle" gorm:"type:citext"`
@@ -11,6 +11,7 @@ type BaseModel struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
+	Version   uint           `json:"version" gorm:"not null;default:1"` // Bumped on every update; sent as ETag, checked against If-Match
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
@@ -0,0 +1,323 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"yaro-wora-be/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BundleFormatVersion is bumped whenever the bundle layout changes in a way older importers can't read
+const BundleFormatVersion = 1
+
+// Import conflict strategies, applied when an imported item matches an existing one
+const (
+	ImportStrategySkip      = "skip"      // Keep the existing item
+	ImportStrategyOverwrite = "overwrite" // Replace the existing item with the imported one
+	ImportStrategyDuplicate = "duplicate" // Create the imported item next to the existing one
+)
+
+// Import item outcomes
+const (
+	ImportActionCreated     = "created"
+	ImportActionOverwritten = "overwritten"
+	ImportActionSkipped     = "skipped"
+	ImportActionFailed      = "failed"
+)
+
+// errImportDryRun rolls back the import transaction of a dry run
+var errImportDryRun = errors.New("dry run")
+
+// ErrInvalidBundle is returned when a bundle can't be read or has an unsupported layout
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// ErrImportFailed is returned when at least one item could not be imported; nothing is written
+var ErrImportFailed = errors.New("import failed")
+
+// Bundle is a portable export of all content. IDs in a bundle are only meaningful within
+// the bundle: references between items are remapped to the new IDs on import.
+type Bundle struct {
+	FormatVersion int                        `json:"format_version"`
+	ExportedAt    time.Time                  `json:"exported_at"`
+	Content       map[string]json.RawMessage `json:"content"` // Content type key -> array of rows
+	Images        []string                   `json:"images"`  // Every image URL the content references
+}
+
+// ImportOptions controls how a bundle is imported
+type ImportOptions struct {
+	Strategy string
+	DryRun   bool
+}
+
+// ImportItemResult reports what happened to a single bundle item
+type ImportItemResult struct {
+	ContentType string `json:"content_type"`
+	BundleID    uint   `json:"bundle_id"`
+	ID          uint   `json:"id,omitempty"` // ID of the created or matched row in this database
+	Label       string `json:"label"`
+	Action      string `json:"action"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ImportReport summarizes an import
+type ImportReport struct {
+	DryRun   bool               `json:"dry_run"`
+	Strategy string             `json:"strategy"`
+	Counts   map[string]int     `json:"counts"` // Action -> number of items
+	Items    []ImportItemResult `json:"items"`
+}
+
+// contentReferences lists the ID columns of a content type and the content types they point at
+var contentReferences = map[string]map[string]string{
+	"destinations": {"category_id": "destination-categories"},
+	"gallery":      {"category_id": "gallery-categories"},
+	"regulations":  {"category_id": "regulation-categories"},
+	"facilities":   {"category_id": "facility-categories"},
+	"news":         {"category_id": "news-categories", "author_id": "news-authors"},
+}
+
+// importKeyColumns overrides the column used to match imported rows for content types with a unique
+// column. Like singletons, these rows can't be duplicated.
+var importKeyColumns = map[string]string{
+	"pricing": "type",
+}
+
+// ContentReferences returns the ID columns of a content type mapped to the content types they point at
+func ContentReferences(key string) map[string]string {
+	return contentReferences[key]
+}
+
+// ExportBundle exports every content type, except trashed rows, into a bundle
+func ExportBundle(db *gorm.DB) (*Bundle, error) {
+	bundle := &Bundle{
+		FormatVersion: BundleFormatVersion,
+		ExportedAt:    time.Now(),
+		Content:       map[string]json.RawMessage{},
+		Images:        []string{},
+	}
+
+	images := map[string]bool{}
+	for _, ct := range ContentTypes() {
+		rows := ct.NewSlice()
+		if err := db.Order("id ASC").Find(rows).Error; err != nil {
+			return nil, fmt.Errorf("export %s: %w", ct.Key, err)
+		}
+
+		items := reflect.ValueOf(rows).Elem()
+		for i := 0; i < items.Len(); i++ {
+			for _, url := range utils.CollectImageURLs(items.Index(i).Addr().Interface()) {
+				images[url] = true
+			}
+		}
+
+		data, err := json.Marshal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", ct.Key, err)
+		}
+		bundle.Content[ct.Key] = data
+	}
+
+	for url := range images {
+		bundle.Images = append(bundle.Images, url)
+	}
+	sort.Strings(bundle.Images)
+
+	return bundle, nil
+}
+
+// ImportBundle imports a bundle in a single transaction. Referenced content types are imported
+// first so category and author IDs can be remapped. When any item fails, or on a dry run,
+// the transaction is rolled back and the report describes what would have happened.
+func ImportBundle(db *gorm.DB, bundle *Bundle, opts ImportOptions) (*ImportReport, error) {
+	if bundle.FormatVersion < 1 || bundle.FormatVersion > BundleFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidBundle, bundle.FormatVersion)
+	}
+	switch opts.Strategy {
+	case ImportStrategySkip, ImportStrategyOverwrite, ImportStrategyDuplicate:
+	default:
+		return nil, fmt.Errorf("%w: unknown import strategy %q", ErrInvalidBundle, opts.Strategy)
+	}
+	for key := range bundle.Content {
+		if _, ok := LookupContentType(key); !ok {
+			return nil, fmt.Errorf("%w: unknown content type %q", ErrInvalidBundle, key)
+		}
+	}
+
+	report := &ImportReport{
+		DryRun:   opts.DryRun,
+		Strategy: opts.Strategy,
+		Counts:   map[string]int{},
+		Items:    []ImportItemResult{},
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Bundle ID -> ID in this database, per content type
+		idMap := map[string]map[uint]uint{}
+
+		for _, ct := range importOrder() {
+			data, ok := bundle.Content[ct.Key]
+			if !ok {
+				continue
+			}
+
+			rows := ct.NewSlice()
+			if err := json.Unmarshal(data, rows); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidBundle, ct.Key, err)
+			}
+
+			idMap[ct.Key] = map[uint]uint{}
+			items := reflect.ValueOf(rows).Elem()
+			for i := 0; i < items.Len(); i++ {
+				result := importItem(tx, ct, items.Index(i).Addr().Interface(), idMap, opts.Strategy)
+				report.Items = append(report.Items, result)
+				report.Counts[result.Action]++
+			}
+		}
+
+		if report.Counts[ImportActionFailed] > 0 {
+			return ErrImportFailed
+		}
+		if opts.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return report, err
+	}
+	return report, nil
+}
+
+// importOrder returns the content types with referenced types (categories, authors) first
+func importOrder() []ContentType {
+	referenced := map[string]bool{}
+	for _, refs := range contentReferences {
+		for _, key := range refs {
+			referenced[key] = true
+		}
+	}
+
+	var first, rest []ContentType
+	for _, ct := range ContentTypes() {
+		if referenced[ct.Key] {
+			first = append(first, ct)
+		} else {
+			rest = append(rest, ct)
+		}
+	}
+	return append(first, rest...)
+}
+
+// importItem imports one row in its own savepoint so a failure leaves the others intact
+func importItem(tx *gorm.DB, ct ContentType, row interface{}, idMap map[string]map[uint]uint, strategy string) ImportItemResult {
+	value := reflect.ValueOf(row).Elem()
+	bundleID := uint(value.FieldByName("ID").Uint())
+	result := ImportItemResult{ContentType: ct.Key, BundleID: bundleID}
+	if ct.LabelColumn != "" {
+		result.Label = jsonField(value, ct.LabelColumn).String()
+	}
+
+	fail := func(err error) ImportItemResult {
+		result.Action = ImportActionFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	// Point references at the rows they were imported as
+	for column, refType := range contentReferences[ct.Key] {
+		field := jsonField(value, column)
+		newID, ok := idMap[refType][uint(field.Uint())]
+		if !ok {
+			return fail(fmt.Errorf("%s %d is not in the bundle", column, field.Uint()))
+		}
+		field.SetUint(uint64(newID))
+	}
+
+	existing, err := findImportMatch(tx, ct, value)
+	if err != nil {
+		return fail(err)
+	}
+
+	unique := ct.Singleton || importKeyColumns[ct.Key] != ""
+	if existing != nil && (strategy == ImportStrategySkip || (unique && strategy == ImportStrategyDuplicate)) {
+		result.ID = uint(reflect.ValueOf(existing).Elem().FieldByName("ID").Uint())
+		result.Action = ImportActionSkipped
+		idMap[ct.Key][bundleID] = result.ID
+		return result
+	}
+
+	err = RetryOnSlugConflict(func() error {
+		return tx.Transaction(func(itemTx *gorm.DB) error {
+			if existing != nil && strategy == ImportStrategyOverwrite {
+				current := reflect.ValueOf(existing).Elem()
+				value.FieldByName("ID").SetUint(current.FieldByName("ID").Uint())
+				value.FieldByName("Version").SetUint(current.FieldByName("Version").Uint() + 1)
+				result.Action = ImportActionOverwritten
+				return itemTx.Omit(clause.Associations).Save(row).Error
+			}
+
+			value.FieldByName("ID").SetUint(0)
+			value.FieldByName("Version").SetUint(1)
+			result.Action = ImportActionCreated
+			return itemTx.Omit(clause.Associations).Create(row).Error
+		})
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	result.ID = uint(value.FieldByName("ID").Uint())
+	idMap[ct.Key][bundleID] = result.ID
+	return result
+}
+
+// findImportMatch finds the existing row an imported row conflicts with: the row of a
+// singleton, the row with the same key column, slug or label. Returns nil if none.
+func findImportMatch(tx *gorm.DB, ct ContentType, value reflect.Value) (interface{}, error) {
+	existing := ct.New()
+	query := tx
+	switch {
+	case ct.Singleton:
+	case importKeyColumns[ct.Key] != "":
+		column := importKeyColumns[ct.Key]
+		query = query.Where(column+" = ?", jsonField(value, column).String())
+	case value.FieldByName("Slugs").IsValid() && jsonField(value, "slug").String() != "":
+		query = query.Where("slug = ?", jsonField(value, "slug").String())
+	case ct.LabelColumn != "":
+		query = query.Where(ct.LabelColumn+" = ?", jsonField(value, ct.LabelColumn).String())
+	}
+
+	err := query.Order("id ASC").First(existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// jsonField finds the struct field with the given JSON name, looking into embedded structs
+func jsonField(value reflect.Value, name string) reflect.Value {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if found := jsonField(value.Field(i), name); found.IsValid() {
+				return found
+			}
+			continue
+		}
+		if strings.Split(field.Tag.Get("json"), ",")[0] == name {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
@@ -2,6 +2,7 @@ package models
 
 type Carousel struct {
 	BaseModel
+	Publication
 	Title         string `json:"title" gorm:"not null"`
 	TitleID       string `json:"title_id" gorm:"not null"`
 	Subtitle      string `json:"subtitle"`
@@ -12,4 +13,6 @@ type Carousel struct {
 	AltTextID     string `json:"alt_text_id"`
 	CarouselOrder int    `json:"carousel_order" gorm:"default:0"`
 	IsActive      bool   `json:"is_active" gorm:"default:true"`
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
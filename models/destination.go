@@ -17,6 +17,8 @@ type DestinationCategory struct {
 
 type Destination struct {
 	BaseModel
+	Publication
+	Slugs
 	Title                     string              `json:"title" gorm:"type:citext;not null"`
 	TitleID                   string              `json:"title_id"`
 	ShortDescription          string              `json:"short_description" gorm:"type:text"`
@@ -36,6 +38,8 @@ type Destination struct {
 	SortOrder                 int                 `json:"sort_order" gorm:"default:0"`
 	CategoryID                uint                `json:"category_id"`
 	DestinationCategory       DestinationCategory `json:"destination_category" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type DestinationSummary struct {
@@ -52,6 +56,10 @@ type DestinationSummary struct {
 	SortOrder           int                 `json:"sort_order"`
 	CategoryID          uint                `json:"category_id"`
 	DestinationCategory DestinationCategory `json:"destination_category"`
+	Publication
+	Slugs
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type DestinationDetailSection struct {
@@ -85,6 +93,8 @@ type DestinationPageContent struct {
 	CTAButtonText                    string `json:"cta_button_text"`
 	CTAButtonTextID                  string `json:"cta_button_text_id"`
 	CTAButtonURL                     string `json:"cta_button_url"`
+
+	HeroImageMeta *ImageMeta `json:"hero_image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 func (Destination) TableName() string {
@@ -96,6 +106,9 @@ func (d *Destination) BeforeCreate(tx *gorm.DB) error {
 	if err := d.ensureSingleFeatured(tx, true); err != nil {
 		return err
 	}
+	if err := d.assignSlugs(tx); err != nil {
+		return err
+	}
 	return d.updateSearchVector(tx)
 }
 
@@ -104,9 +117,16 @@ func (d *Destination) BeforeUpdate(tx *gorm.DB) error {
 	if err := d.ensureSingleFeatured(tx, false); err != nil {
 		return err
 	}
+	if err := d.assignSlugs(tx); err != nil {
+		return err
+	}
 	return d.updateSearchVector(tx)
 }
 
+func (d *Destination) assignSlugs(tx *gorm.DB) error {
+	return d.Slugs.assign(tx, "destinations", d.ID, d.Title, d.TitleID)
+}
+
 func (d *Destination) updateSearchVector(tx *gorm.DB) error {
 	sql := `
 		UPDATE destinations 
@@ -15,6 +15,8 @@ type FacilityCategory struct {
 
 type Facility struct {
 	BaseModel
+	Publication
+	Slugs
 	Name                   string           `json:"name" gorm:"type:citext;not null"`
 	NameID                 string           `json:"name_id"`
 	ShortDescription       string           `json:"short_description" gorm:"type:text"`
@@ -39,6 +41,8 @@ type Facility struct {
 	CTAUrl                 string           `json:"cta_url"`
 	IsFeatured             bool             `json:"is_featured" gorm:"default:false"`
 	SortOrder              int              `json:"sort_order" gorm:"default:0"`
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type FacilitySummary struct {
@@ -61,6 +65,10 @@ type FacilitySummary struct {
 	DurationID         string           `json:"duration_id"`
 	CapacityID         string           `json:"capacity_id"`
 	PriceID            string           `json:"price_id"`
+	Publication
+	Slugs
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type FacilityDetailSection struct {
@@ -98,14 +106,24 @@ func (Facility) TableName() string {
 
 // BeforeCreate hook to update search vector
 func (f *Facility) BeforeCreate(tx *gorm.DB) error {
+	if err := f.assignSlugs(tx); err != nil {
+		return err
+	}
 	return f.updateSearchVector(tx)
 }
 
 // BeforeUpdate hook to update search vector
 func (f *Facility) BeforeUpdate(tx *gorm.DB) error {
+	if err := f.assignSlugs(tx); err != nil {
+		return err
+	}
 	return f.updateSearchVector(tx)
 }
 
+func (f *Facility) assignSlugs(tx *gorm.DB) error {
+	return f.Slugs.assign(tx, "facilities", f.ID, f.Name, f.NameID)
+}
+
 func (f *Facility) updateSearchVector(tx *gorm.DB) error {
 	sql := `
 		UPDATE facilities 
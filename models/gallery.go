@@ -17,6 +17,8 @@ type GalleryCategory struct {
 
 type GalleryImage struct {
 	BaseModel
+	Publication
+	Slugs
 	Title              string          `json:"title" gorm:"type:citext;not null"`
 	TitleID            string          `json:"title_id"`
 	ShortDescription   string          `json:"short_description" gorm:"type:text"`
@@ -34,6 +36,11 @@ type GalleryImage struct {
 	Tags               datatypes.JSON  `json:"tags" gorm:"type:jsonb"`    // array of strings
 	TagsID             datatypes.JSON  `json:"tags_id" gorm:"type:jsonb"` // array of strings
 	DateUploaded       time.Time       `json:"date_uploaded"`
+	MediaType          string          `json:"media_type" gorm:"size:20;not null;default:image"`
+	VideoURL           string          `json:"video_url"`        // MP4/WebM; ImageURL is its poster frame
+	Duration           float64         `json:"duration_seconds"` // Videos only
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type GalleryImageSummary struct {
@@ -47,6 +54,13 @@ type GalleryImageSummary struct {
 	CategoryID         uint            `json:"category_id"`
 	GalleryCategory    GalleryCategory `json:"gallery_category" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	DateUploaded       time.Time       `json:"date_uploaded"`
+	MediaType          string          `json:"media_type"`
+	VideoURL           string          `json:"video_url,omitempty"`
+	Duration           float64         `json:"duration_seconds,omitempty"`
+	Publication
+	Slugs
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type GalleryPageContent struct {
@@ -57,6 +71,8 @@ type GalleryPageContent struct {
 	TitleID               string `json:"title_id"`
 	Subtitle              string `json:"subtitle"`
 	SubtitleID            string `json:"subtitle_id"`
+
+	HeroImageMeta *ImageMeta `json:"hero_image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 // Override the BaseModel ID field for string ID
@@ -70,14 +86,24 @@ func (GalleryImage) TableName() string {
 
 // BeforeCreate hook to update search vector
 func (d *GalleryImage) BeforeCreate(tx *gorm.DB) error {
+	if err := d.assignSlugs(tx); err != nil {
+		return err
+	}
 	return d.updateSearchVector(tx)
 }
 
 // BeforeUpdate hook to update search vector
 func (d *GalleryImage) BeforeUpdate(tx *gorm.DB) error {
+	if err := d.assignSlugs(tx); err != nil {
+		return err
+	}
 	return d.updateSearchVector(tx)
 }
 
+func (d *GalleryImage) assignSlugs(tx *gorm.DB) error {
+	return d.Slugs.assign(tx, "gallery", d.ID, d.Title, d.TitleID)
+}
+
 func (d *GalleryImage) updateSearchVector(tx *gorm.DB) error {
 	sql := `
 		UPDATE gallery_images 
@@ -2,10 +2,13 @@ package models
 
 import (
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 type Heritage struct {
 	BaseModel
+	Publication
+	Slugs
 	Title                  string         `json:"title" gorm:"type:citext;not null"`
 	TitleID                string         `json:"title_id"`
 	ShortDescription       string         `json:"short_description" gorm:"type:text"`
@@ -16,6 +19,8 @@ type Heritage struct {
 	ThumbnailURL           string         `json:"thumbnail_url"`
 	HeritageDetailSections datatypes.JSON `json:"heritage_detail_sections" gorm:"type:jsonb"`
 	SortOrder              int            `json:"sort_order" gorm:"default:0"`
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type HeritageSummary struct {
@@ -27,6 +32,10 @@ type HeritageSummary struct {
 	ImageURL           string `json:"image_url"`
 	ThumbnailURL       string `json:"thumbnail_url"`
 	SortOrder          int    `json:"sort_order"`
+	Publication
+	Slugs
+
+	ImageMeta *ImageMeta `json:"image_meta,omitempty" gorm:"-"` // Placeholders, focal point and variants from the media library, set in responses
 }
 
 type HeritageDetailSection struct {
@@ -61,3 +70,17 @@ type HeritagePageContent struct {
 func (Heritage) TableName() string {
 	return "heritages"
 }
+
+// BeforeCreate hook to generate slugs
+func (h *Heritage) BeforeCreate(tx *gorm.DB) error {
+	return h.assignSlugs(tx)
+}
+
+// BeforeUpdate hook to keep slugs in sync with the title
+func (h *Heritage) BeforeUpdate(tx *gorm.DB) error {
+	return h.assignSlugs(tx)
+}
+
+func (h *Heritage) assignSlugs(tx *gorm.DB) error {
+	return h.Slugs.assign(tx, "heritage", h.ID, h.Title, h.TitleID)
+}
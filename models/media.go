@@ -0,0 +1,561 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+	"yaro-wora-be/utils"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// MediaAsset is an uploaded file in R2 and its metadata
+type MediaAsset struct {
+	BaseModel
+	Key           string         `json:"key" gorm:"size:512;not null;uniqueIndex"`
+	URL           string         `json:"url" gorm:"not null;index"`
+	ThumbnailKey  string         `json:"thumbnail_key"`
+	ThumbnailURL  string         `json:"thumbnail_url"`
+	Folder        string         `json:"folder" gorm:"size:255;index"`
+	Filename      string         `json:"filename" gorm:"type:citext"` // Name of the file as uploaded
+	ContentType   string         `json:"content_type" gorm:"size:100"`
+	Size          int64          `json:"size"`
+	Width         int            `json:"width"`
+	Height        int            `json:"height"`
+	Variants      datatypes.JSON `json:"variants" gorm:"type:jsonb"` // Responsive variants by name, see utils.ImageVariant
+	SrcSet        string         `json:"srcset" gorm:"type:text"`
+	FocalX        float64        `json:"focal_x" gorm:"not null;default:50"` // Focal point in percent of the width
+	FocalY        float64        `json:"focal_y" gorm:"not null;default:50"` // Focal point in percent of the height
+	CropX         float64        `json:"crop_x"`                             // Crop rectangle in percent, unused while CropWidth is 0
+	CropY         float64        `json:"crop_y"`
+	CropWidth     float64        `json:"crop_width"`
+	CropHeight    float64        `json:"crop_height"`
+	BlurHash      string         `json:"blurhash" gorm:"size:100"`
+	LQIP          string         `json:"lqip" gorm:"type:text"` // Tiny base64 data URI shown while the image loads
+	DominantColor string         `json:"dominant_color" gorm:"size:7"`
+	CapturedAt    *time.Time     `json:"captured_at"` // EXIF capture data, kept here only; published files are stripped
+	CameraMake    string         `json:"camera_make"`
+	CameraModel   string         `json:"camera_model"`
+	Artist        string         `json:"artist"`
+	Latitude      *float64       `json:"latitude"`
+	Longitude     *float64       `json:"longitude"`
+	AltText       string         `json:"alt_text" gorm:"type:text"`
+	AltTextID     string         `json:"alt_text_id" gorm:"type:text"`
+	UploadedByID  *uint          `json:"uploaded_by_id"`
+	UploadedBy    string         `json:"uploaded_by"`
+
+	MediaType string  `json:"media_type" gorm:"size:20;not null;default:image"` // image, video or panorama
+	Duration  float64 `json:"duration_seconds"`                                 // Videos only
+	PosterURL string  `json:"poster_url"`                                       // Poster frame of a video, a media asset of its own
+
+	ContentHash string `json:"content_hash" gorm:"size:64;index"` // SHA-256 of the file as uploaded, for deduplication
+}
+
+func (MediaAsset) TableName() string {
+	return "media_assets"
+}
+
+// MediaUpload is a presigned upload slot. The file is PUT straight to storage at Key and
+// becomes a MediaAsset once the upload is completed.
+type MediaUpload struct {
+	BaseModel
+	Key          string     `json:"key" gorm:"size:512;not null;uniqueIndex"` // Staging key the file is uploaded to
+	Folder       string     `json:"folder" gorm:"size:255"`
+	Filename     string     `json:"filename"`
+	ContentType  string     `json:"content_type" gorm:"size:100"`
+	Size         int64      `json:"size"`
+	MediaType    string     `json:"media_type" gorm:"size:20"`   // Upload an image as a panorama
+	ContentHash  string     `json:"content_hash" gorm:"size:64"` // SHA-256 declared by the client, checked on completion
+	AltText      string     `json:"alt_text" gorm:"type:text"`
+	AltTextID    string     `json:"alt_text_id" gorm:"type:text"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	AssetID      *uint      `json:"asset_id"`
+	UploadedByID *uint      `json:"uploaded_by_id"`
+	UploadedBy   string     `json:"uploaded_by"`
+}
+
+func (MediaUpload) TableName() string {
+	return "media_uploads"
+}
+
+// FindDuplicateAsset returns the asset uploaded earlier with the same content hash (and media
+// type, when given), or nil. Assets whose file has gone from storage don't count.
+func FindDuplicateAsset(db *gorm.DB, contentHash, mediaType string) *MediaAsset {
+	query := db.Where("content_hash = ?", contentHash)
+	if mediaType != "" {
+		query = query.Where("media_type = ?", mediaType)
+	}
+
+	var assets []MediaAsset
+	if err := query.Order("id ASC").Find(&assets).Error; err != nil {
+		return nil
+	}
+	for _, asset := range assets {
+		if _, err := utils.Storage.StatObject(asset.Key); err == nil {
+			return &asset
+		}
+	}
+	return nil
+}
+
+// UploadResponse describes the asset the way a fresh upload of its file would
+func (a MediaAsset) UploadResponse() *utils.UploadResponse {
+	response := &utils.UploadResponse{
+		Success:         true,
+		FileURL:         a.URL,
+		ThumbnailURL:    a.ThumbnailURL,
+		FileSize:        a.Size,
+		Key:             a.Key,
+		ThumbnailKey:    a.ThumbnailKey,
+		ContentType:     a.ContentType,
+		SrcSet:          a.SrcSet,
+		MediaType:       a.MediaType,
+		DurationSeconds: a.Duration,
+		ContentHash:     a.ContentHash,
+	}
+	if a.Width > 0 {
+		response.Dimensions = &utils.ImageDimensions{Width: a.Width, Height: a.Height}
+	}
+	if len(a.Variants) > 0 {
+		json.Unmarshal(a.Variants, &response.Variants)
+	}
+	if a.BlurHash != "" || a.LQIP != "" {
+		response.ImagePlaceholder = &utils.ImagePlaceholder{BlurHash: a.BlurHash, LQIP: a.LQIP, DominantColor: a.DominantColor}
+	}
+	return response
+}
+
+// PrefillGalleryImage fills the date, photographer and location of a gallery image that were
+// left empty from the capture data of the photo
+func (a MediaAsset) PrefillGalleryImage(image *GalleryImage) {
+	if image.DateUploaded.IsZero() && a.CapturedAt != nil {
+		image.DateUploaded = *a.CapturedAt
+	}
+	if image.Photographer == "" {
+		image.Photographer = a.Artist
+	}
+	if image.Location == "" {
+		image.Location = utils.FormatCoordinates(a.Latitude, a.Longitude)
+	}
+}
+
+// Focus returns the focal point and crop rectangle that cropped variants are generated with
+func (a MediaAsset) Focus() utils.ImageFocus {
+	focus := utils.ImageFocus{FocalX: a.FocalX, FocalY: a.FocalY}
+	if a.CropWidth > 0 && a.CropHeight > 0 {
+		focus.Crop = &utils.ImageCrop{X: a.CropX, Y: a.CropY, Width: a.CropWidth, Height: a.CropHeight}
+	}
+	return focus
+}
+
+// FocalPoint is a point of an image in percent of its width and height
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// ImageMeta is the media library metadata exposed next to an image URL so the frontend
+// can crop it around its focal point, build a srcset and show a placeholder while it loads
+type ImageMeta struct {
+	Width      int              `json:"width,omitempty"`
+	Height     int              `json:"height,omitempty"`
+	FocalPoint FocalPoint       `json:"focal_point"`
+	Crop       *utils.ImageCrop `json:"crop,omitempty"`
+	SrcSet     string           `json:"srcset,omitempty"`
+	Variants   datatypes.JSON   `json:"variants,omitempty"`
+
+	BlurHash      string `json:"blurhash,omitempty"`
+	LQIP          string `json:"lqip,omitempty"`
+	DominantColor string `json:"dominant_color,omitempty"`
+}
+
+// Meta returns the metadata exposed next to the asset's URL
+func (a MediaAsset) Meta() *ImageMeta {
+	focus := a.Focus()
+	return &ImageMeta{
+		Width:      a.Width,
+		Height:     a.Height,
+		FocalPoint: FocalPoint{X: focus.FocalX, Y: focus.FocalY},
+		Crop:       focus.Crop,
+		SrcSet:     a.SrcSet,
+		Variants:   a.Variants,
+
+		BlurHash:      a.BlurHash,
+		LQIP:          a.LQIP,
+		DominantColor: a.DominantColor,
+	}
+}
+
+// LookupImageMeta returns the metadata of the media assets with the given URLs, by URL.
+// URLs that are not in the media library (external images, older uploads) are left out.
+func LookupImageMeta(db *gorm.DB, urls ...string) map[string]*ImageMeta {
+	meta := map[string]*ImageMeta{}
+
+	var wanted []string
+	for _, url := range urls {
+		if url != "" {
+			wanted = append(wanted, url)
+		}
+	}
+	if len(wanted) == 0 {
+		return meta
+	}
+
+	var assets []MediaAsset
+	if err := db.Where("url IN ?", wanted).Find(&assets).Error; err != nil {
+		return meta
+	}
+	for _, asset := range assets {
+		meta[asset.URL] = asset.Meta()
+	}
+	return meta
+}
+
+// MediaUsage is a place in the content where an asset is used. Usages are persisted in
+// media_usages and kept up to date by callbacks on every save, see RegisterMediaUsageCallbacks.
+type MediaUsage struct {
+	ID          uint   `json:"-" gorm:"primaryKey"`
+	URL         string `json:"-" gorm:"not null;index"`
+	ContentType string `json:"content_type" gorm:"size:50;not null;index:idx_media_usages_content"`
+	ContentID   uint   `json:"content_id" gorm:"not null;index:idx_media_usages_content"`
+	Label       string `json:"label"`
+	Path        string `json:"path" gorm:"type:text"` // JSON path of the field, e.g. "destination_detail_sections[0].image_url"
+	Trashed     bool   `json:"trashed"`               // The content is in the trash and still holds on to the asset
+	Revision    bool   `json:"revision"`              // Only an earlier revision uses the asset; kept so it can be restored
+}
+
+func (MediaUsage) TableName() string {
+	return "media_usages"
+}
+
+// MediaUsageIndex maps image and thumbnail URLs to the content that uses them
+type MediaUsageIndex map[string][]MediaUsage
+
+// Usages returns where an asset is used, through either its image or its thumbnail
+func (idx MediaUsageIndex) Usages(asset MediaAsset) []MediaUsage {
+	usages := append([]MediaUsage{}, idx[asset.URL]...)
+	if asset.ThumbnailURL != "" {
+		usages = append(usages, idx[asset.ThumbnailURL]...)
+	}
+	return usages
+}
+
+// BuildMediaUsageIndex returns every usage of every image, including usages by trashed content
+func BuildMediaUsageIndex(db *gorm.DB) (MediaUsageIndex, error) {
+	var usages []MediaUsage
+	if err := db.Order("id ASC").Find(&usages).Error; err != nil {
+		return nil, fmt.Errorf("load media usages: %w", err)
+	}
+	return newMediaUsageIndex(usages), nil
+}
+
+// FindMediaUsages returns the usages of the given image and thumbnail URLs only
+func FindMediaUsages(db *gorm.DB, urls []string) (MediaUsageIndex, error) {
+	if len(urls) == 0 {
+		return MediaUsageIndex{}, nil
+	}
+	var usages []MediaUsage
+	if err := db.Where("url IN ?", urls).Order("id ASC").Find(&usages).Error; err != nil {
+		return nil, fmt.Errorf("load media usages: %w", err)
+	}
+	return newMediaUsageIndex(usages), nil
+}
+
+// MediaUsedCondition is an SQL condition on media_assets that holds when the asset is in use,
+// for filtering listings without loading the usages
+const MediaUsedCondition = "EXISTS (SELECT 1 FROM media_usages WHERE media_usages.url IN (media_assets.url, media_assets.thumbnail_url))"
+
+func newMediaUsageIndex(usages []MediaUsage) MediaUsageIndex {
+	index := MediaUsageIndex{}
+	for _, usage := range usages {
+		index[usage.URL] = append(index[usage.URL], usage)
+	}
+	return index
+}
+
+// MigrateMediaUsages creates the media_usages table and fills it from the existing content.
+// The table is created and filled in one transaction, so this only runs once; after that the
+// callbacks keep it up to date. Use the reindex-media command to rebuild it after changes
+// made by hand.
+func MigrateMediaUsages(db *gorm.DB) error {
+	if db.Migrator().HasTable(&MediaUsage{}) {
+		return db.AutoMigrate(&MediaUsage{})
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&MediaUsage{}); err != nil {
+			return err
+		}
+		return RebuildMediaUsages(tx)
+	})
+}
+
+// RebuildMediaUsages scans every content row, including trashed ones, and every revision
+// snapshot, and replaces the persisted usage index
+func RebuildMediaUsages(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&MediaUsage{}).Error; err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		var revisions []ContentRevision
+		if err := tx.FindInBatches(&revisions, 200, func(_ *gorm.DB, _ int) error {
+			return indexRevisionUsages(tx, revisions, seen)
+		}).Error; err != nil {
+			return err
+		}
+
+		// Also drops the revision usages of permanently deleted content
+		for _, ct := range ContentTypes() {
+			if err := reindexMediaUsages(tx, ct, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RegisterMediaUsageCallbacks keeps media_usages in step with the content: after every create,
+// update and delete of a content row, its usages are collected again in the same transaction,
+// and the images of every new revision are added
+func RegisterMediaUsageCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("media_usages:create", updateMediaUsages); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("media_usages:revision", addRevisionUsages); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("media_usages:update", updateMediaUsages); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("media_usages:delete", updateMediaUsages)
+}
+
+// updateMediaUsages reindexes the rows a statement wrote. Statements that don't carry the
+// primary keys of their rows (e.g. bulk updates by condition) reindex the whole content type.
+func updateMediaUsages(tx *gorm.DB) {
+	if tx.Error != nil || tx.RowsAffected == 0 || tx.Statement.Schema == nil {
+		return
+	}
+	ct, ok := contentTypeOfModel(tx.Statement.Schema.ModelType)
+	if !ok {
+		return
+	}
+
+	db := tx.Session(&gorm.Session{NewDB: true})
+	if err := reindexMediaUsages(db, ct, statementIDs(tx.Statement)); err != nil {
+		tx.AddError(fmt.Errorf("update media usages of %s: %w", ct.Key, err))
+	}
+}
+
+// reindexMediaUsages replaces the usages of the given rows, or of every row when ids is empty.
+// Usages by revisions are kept until their content is permanently deleted.
+func reindexMediaUsages(db *gorm.DB, ct ContentType, ids []uint) error {
+	remove := db.Where("content_type = ? AND revision = ?", ct.Key, false)
+	removeRevisions := db.Where("content_type = ? AND revision = ?", ct.Key, true).
+		Where("content_id NOT IN (?)", db.Unscoped().Model(ct.New()).Select("id"))
+	load := db.Unscoped().Order("id ASC")
+	if len(ids) > 0 {
+		remove = remove.Where("content_id IN ?", ids)
+		removeRevisions = removeRevisions.Where("content_id IN ?", ids)
+		load = load.Where("id IN ?", ids)
+	}
+	if err := remove.Delete(&MediaUsage{}).Error; err != nil {
+		return err
+	}
+	if err := removeRevisions.Delete(&MediaUsage{}).Error; err != nil {
+		return err
+	}
+
+	rows := ct.NewSlice()
+	if err := load.Find(rows).Error; err != nil {
+		return fmt.Errorf("scan %s: %w", ct.Key, err)
+	}
+	usages := collectMediaUsages(ct, rows)
+	if len(usages) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(usages, 500).Error
+}
+
+// collectMediaUsages returns the images used by rows, a pointer to a slice of ct's model
+func collectMediaUsages(ct ContentType, rows interface{}) []MediaUsage {
+	var usages []MediaUsage
+	items := reflect.ValueOf(rows).Elem()
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i)
+		base := item.FieldByName("BaseModel").Interface().(BaseModel)
+
+		label := ct.Name
+		if ct.LabelColumn != "" {
+			label = jsonField(item, ct.LabelColumn).String()
+		}
+
+		for _, ref := range utils.CollectImageRefs(item.Addr().Interface()) {
+			usages = append(usages, MediaUsage{
+				URL:         ref.URL,
+				ContentType: ct.Key,
+				ContentID:   base.ID,
+				Label:       label,
+				Path:        ref.Path,
+				Trashed:     base.DeletedAt.Valid,
+			})
+		}
+	}
+	return usages
+}
+
+// addRevisionUsages indexes the images of revisions as they are recorded
+func addRevisionUsages(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.Schema.ModelType != reflect.TypeOf(ContentRevision{}) {
+		return
+	}
+
+	var revisions []ContentRevision
+	switch value := reflect.Indirect(tx.Statement.ReflectValue); value.Kind() {
+	case reflect.Struct:
+		revisions = append(revisions, value.Interface().(ContentRevision))
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			revisions = append(revisions, reflect.Indirect(value.Index(i)).Interface().(ContentRevision))
+		}
+	}
+
+	db := tx.Session(&gorm.Session{NewDB: true})
+	seen := map[string]bool{}
+	for _, revision := range revisions {
+		var indexed []string
+		if err := db.Model(&MediaUsage{}).
+			Where("content_type = ? AND content_id = ? AND revision = ?", revision.ContentType, revision.ContentID, true).
+			Pluck("url", &indexed).Error; err != nil {
+			tx.AddError(fmt.Errorf("update media usages of revisions: %w", err))
+			return
+		}
+		for _, url := range indexed {
+			seen[revisionUsageKey(revision.ContentType, revision.ContentID, url)] = true
+		}
+	}
+	if err := indexRevisionUsages(db, revisions, seen); err != nil {
+		tx.AddError(fmt.Errorf("update media usages of revisions: %w", err))
+	}
+}
+
+// indexRevisionUsages adds the images in revision snapshots, once per image and content.
+// seen holds the keys (see revisionUsageKey) of usages that are indexed already.
+func indexRevisionUsages(db *gorm.DB, revisions []ContentRevision, seen map[string]bool) error {
+	var usages []MediaUsage
+	for _, revision := range revisions {
+		ct, ok := LookupContentType(revision.ContentType)
+		if !ok {
+			continue
+		}
+		rows := ct.NewSlice()
+		if err := json.Unmarshal([]byte("["+string(revision.Snapshot)+"]"), rows); err != nil {
+			continue
+		}
+		for _, usage := range collectMediaUsages(ct, rows) {
+			key := revisionUsageKey(ct.Key, revision.ContentID, usage.URL)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			usage.ContentID = revision.ContentID
+			usage.Trashed = false
+			usage.Revision = true
+			usages = append(usages, usage)
+		}
+	}
+	if len(usages) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(usages, 500).Error
+}
+
+func revisionUsageKey(contentType string, contentID uint, url string) string {
+	return fmt.Sprintf("%s/%d/%s", contentType, contentID, url)
+}
+
+// contentTypeOfModel returns the content type whose model is t
+func contentTypeOfModel(t reflect.Type) (ContentType, bool) {
+	for _, ct := range contentTypes {
+		if reflect.TypeOf(ct.New()).Elem() == t {
+			return ct, true
+		}
+	}
+	return ContentType{}, false
+}
+
+// statementIDs returns the primary keys of the rows a statement was given, or nil when any of
+// them is unknown
+func statementIDs(stmt *gorm.Statement) []uint {
+	value := reflect.Indirect(stmt.ReflectValue)
+	var rows []reflect.Value
+	switch value.Kind() {
+	case reflect.Struct:
+		rows = []reflect.Value{value}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, reflect.Indirect(value.Index(i)))
+		}
+	default:
+		return nil
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		if row.Kind() != reflect.Struct || row.Type() != stmt.Schema.ModelType {
+			return nil
+		}
+		id, ok := row.FieldByName("ID").Interface().(uint)
+		if !ok || id == 0 {
+			return nil
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+// DeleteUnreferencedImages deletes the images of permanently deleted rows from storage together
+// with their media library records. Uploads are deduplicated, so images that other content
+// (including trashed content and its revisions) still uses are kept. Must only be called once the rows are gone.
+func DeleteUnreferencedImages(db *gorm.DB, rows ...interface{}) {
+	if utils.Storage == nil || len(rows) == 0 {
+		return
+	}
+
+	var urls []string
+	for _, row := range rows {
+		urls = append(urls, utils.CollectImageURLs(row)...)
+	}
+	usageIndex, err := FindMediaUsages(db, urls)
+	if err != nil {
+		fmt.Printf("Warning: Failed to check image usage, keeping images of deleted content: %v\n", err)
+		return
+	}
+
+	var deleted []string
+	for _, row := range rows {
+		for _, url := range utils.CollectImageURLs(row) {
+			if len(usageIndex[url]) > 0 {
+				continue
+			}
+			if err := utils.Storage.DeleteImageWithThumbnailIfR2(url); err != nil {
+				fmt.Printf("Warning: Failed to delete image from R2: %v\n", err)
+				continue
+			}
+			deleted = append(deleted, url)
+		}
+	}
+
+	if len(deleted) > 0 {
+		if err := db.Unscoped().Where("url IN ?", deleted).Delete(&MediaAsset{}).Error; err != nil {
+			fmt.Printf("Warning: Failed to remove media records of deleted images: %v\n", err)
+		}
+	}
+}
@@ -9,6 +9,10 @@ import (
 func AutoMigrate() {
 	db := config.DB
 
+	if err := RenumberRevisions(db); err != nil {
+		log.Fatalf("Failed to renumber revisions: %v", err)
+	}
+
 	err := db.AutoMigrate(
 		// Core models
 		&User{},
@@ -62,11 +66,35 @@ func AutoMigrate() {
 
 		// Analytics models
 		&Visitor{},
+
+		// Revision history
+		&ContentRevision{},
+
+		// Slug redirects
+		&SlugRedirect{},
+
+		// Media library
+		&MediaAsset{},
+		&MediaUpload{},
+
+		// Storage usage history
+		&StorageUsageSnapshot{},
 	)
 
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	if err := EnsureUniqueSlugs(db); err != nil {
+		log.Fatalf("Failed to generate slugs: %v", err)
+	}
+
+	if err := RegisterMediaUsageCallbacks(db); err != nil {
+		log.Fatalf("Failed to register media usage callbacks: %v", err)
+	}
+	if err := MigrateMediaUsages(db); err != nil {
+		log.Fatalf("Failed to index media usage: %v", err)
+	}
+
 	log.Println("Database migration completed successfully")
 }
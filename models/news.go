@@ -24,6 +24,8 @@ type NewsAuthor struct {
 
 type NewsArticle struct {
 	BaseModel
+	Publication
+	Slugs
 	Title          string         `json:"title" gorm:"type:citext;not null"`
 	TitleID        string         `json:"title_id"`
 	Excerpt        string         `json:"excerpt" gorm:"type:text"`
@@ -58,6 +60,8 @@ type NewsArticleSummary struct {
 	Tags          datatypes.JSON `json:"tags"`
 	ReadTime      int            `json:"read_time"`
 	IsHeadline    bool           `json:"is_headline"`
+	Publication
+	Slugs
 }
 
 type NewsPageContent struct {
@@ -81,6 +85,9 @@ func (n *NewsArticle) BeforeCreate(tx *gorm.DB) error {
 	if err := n.ensureSingleHighlighted(tx, true); err != nil {
 		return err
 	}
+	if err := n.assignSlugs(tx); err != nil {
+		return err
+	}
 	return n.updateSearchVector(tx)
 }
 
@@ -89,9 +96,16 @@ func (n *NewsArticle) BeforeUpdate(tx *gorm.DB) error {
 	if err := n.ensureSingleHighlighted(tx, false); err != nil {
 		return err
 	}
+	if err := n.assignSlugs(tx); err != nil {
+		return err
+	}
 	return n.updateSearchVector(tx)
 }
 
+func (n *NewsArticle) assignSlugs(tx *gorm.DB) error {
+	return n.Slugs.assign(tx, "news", n.ID, n.Title, n.TitleID)
+}
+
 // ensureSingleHighlighted validates that only one destination can have is_headline = true
 func (d *NewsArticle) ensureSingleHighlighted(tx *gorm.DB, isCreate bool) error {
 	if !d.IsHeadline {
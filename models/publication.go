@@ -0,0 +1,134 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Publication statuses
+const (
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// Publication holds the publication lifecycle fields shared by all content types.
+// Existing rows default to published so content stays visible after migration.
+type Publication struct {
+	Status      string     `json:"status" gorm:"type:varchar(20);default:published;index"`
+	PublishAt   *time.Time `json:"publish_at" gorm:"index"`
+	UnpublishAt *time.Time `json:"unpublish_at" gorm:"index"`
+}
+
+// IsValidStatus checks if the given status is a known publication status
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusDraft, StatusScheduled, StatusPublished, StatusArchived:
+		return true
+	}
+	return false
+}
+
+// Normalize validates the publication fields and derives the effective status.
+// An empty status is treated as published (or scheduled when publish_at is in the future)
+// to keep clients that don't send a status working as before.
+func (p *Publication) Normalize(now time.Time) error {
+	if p.Status == "" {
+		p.Status = StatusPublished
+	}
+
+	if !IsValidStatus(p.Status) {
+		return errors.New("status must be one of draft, scheduled, published, archived")
+	}
+
+	if p.PublishAt != nil && p.UnpublishAt != nil && !p.UnpublishAt.After(*p.PublishAt) {
+		return errors.New("unpublish_at must be after publish_at")
+	}
+
+	switch p.Status {
+	case StatusScheduled:
+		if p.PublishAt == nil {
+			return errors.New("publish_at is required for scheduled content")
+		}
+		if !p.PublishAt.After(now) {
+			p.Status = StatusPublished
+		}
+	case StatusPublished:
+		if p.PublishAt != nil && p.PublishAt.After(now) {
+			p.Status = StatusScheduled
+		}
+	}
+
+	return nil
+}
+
+// IsLive reports whether the content should be publicly visible at the given time
+func (p Publication) IsLive(now time.Time) bool {
+	if p.Status != StatusPublished && p.Status != StatusScheduled {
+		return false
+	}
+	if p.PublishAt != nil && p.PublishAt.After(now) {
+		return false
+	}
+	if p.UnpublishAt != nil && !p.UnpublishAt.After(now) {
+		return false
+	}
+	return true
+}
+
+// Published is a GORM scope that restricts a query to content that is live right now.
+// It checks the timestamps as well so visibility is correct between scheduler runs.
+func Published(db *gorm.DB) *gorm.DB {
+	now := time.Now()
+	return db.
+		Where("status IN ?", []string{StatusPublished, StatusScheduled}).
+		Where("publish_at IS NULL OR publish_at <= ?", now).
+		Where("unpublish_at IS NULL OR unpublish_at > ?", now)
+}
+
+// PublishableModels returns all models that embed Publication
+func PublishableModels() []interface{} {
+	return []interface{}{
+		&Carousel{},
+		&WhyVisit{},
+		&SellingPoint{},
+		&Attraction{},
+		&Destination{},
+		&GalleryImage{},
+		&Regulation{},
+		&Facility{},
+		&NewsArticle{},
+		&Heritage{},
+	}
+}
+
+// ApplyScheduledPublications flips scheduled content whose publish_at has passed to published
+// and archives published content whose unpublish_at has passed
+func ApplyScheduledPublications(db *gorm.DB, now time.Time) (published int64, archived int64, err error) {
+	// Skip hooks so search vector / featured checks don't run for a status-only change
+	tx := db.Session(&gorm.Session{SkipHooks: true})
+
+	for _, model := range PublishableModels() {
+		result := tx.Model(model).
+			Where("status = ? AND publish_at <= ?", StatusScheduled, now).
+			Where("unpublish_at IS NULL OR unpublish_at > ?", now).
+			Update("status", StatusPublished)
+		if result.Error != nil {
+			return published, archived, result.Error
+		}
+		published += result.RowsAffected
+
+		result = tx.Model(model).
+			Where("status IN ? AND unpublish_at <= ?", []string{StatusPublished, StatusScheduled}, now).
+			Update("status", StatusArchived)
+		if result.Error != nil {
+			return published, archived, result.Error
+		}
+		archived += result.RowsAffected
+	}
+
+	return published, archived, nil
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublicationNormalize(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	later := now.Add(2 * time.Hour)
+
+	tests := []struct {
+		name       string
+		in         Publication
+		wantStatus string
+		wantErr    bool
+	}{
+		{name: "empty status is published", in: Publication{}, wantStatus: StatusPublished},
+		{name: "empty status with a future publish_at is scheduled", in: Publication{PublishAt: &future}, wantStatus: StatusScheduled},
+		{name: "published in the future becomes scheduled", in: Publication{Status: StatusPublished, PublishAt: &future}, wantStatus: StatusScheduled},
+		{name: "published in the past stays published", in: Publication{Status: StatusPublished, PublishAt: &past}, wantStatus: StatusPublished},
+		{name: "scheduled in the past becomes published", in: Publication{Status: StatusScheduled, PublishAt: &past}, wantStatus: StatusPublished},
+		{name: "scheduled at now becomes published", in: Publication{Status: StatusScheduled, PublishAt: &now}, wantStatus: StatusPublished},
+		{name: "scheduled in the future stays scheduled", in: Publication{Status: StatusScheduled, PublishAt: &future}, wantStatus: StatusScheduled},
+		{name: "draft keeps its status", in: Publication{Status: StatusDraft, PublishAt: &past}, wantStatus: StatusDraft},
+		{name: "archived keeps its status", in: Publication{Status: StatusArchived}, wantStatus: StatusArchived},
+		{name: "publication window", in: Publication{Status: StatusPublished, PublishAt: &past, UnpublishAt: &later}, wantStatus: StatusPublished},
+		{name: "scheduled without publish_at", in: Publication{Status: StatusScheduled}, wantErr: true},
+		{name: "unknown status", in: Publication{Status: "hidden"}, wantErr: true},
+		{name: "unpublish_at before publish_at", in: Publication{PublishAt: &future, UnpublishAt: &past}, wantErr: true},
+		{name: "unpublish_at equal to publish_at", in: Publication{PublishAt: &future, UnpublishAt: &future}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.in
+			err := p.Normalize(now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && p.Status != tt.wantStatus {
+				t.Errorf("Normalize() status = %q, want %q", p.Status, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package models
+
+// ContentType describes a content model that admin tooling (revisions, trash, export, ...)
+// can address generically by key
+type ContentType struct {
+	Key         string             // URL-friendly identifier, e.g. "destinations"
+	Name        string             // Human readable name
+	Singleton   bool               // Page-content style model with a single row
+	LabelColumn string             // Column used to label rows in listings (title, name, ...)
+	New         func() interface{} // Returns a pointer to a new zero model
+	NewSlice    func() interface{} // Returns a pointer to an empty slice of the model
+}
+
+var contentTypes = []ContentType{
+	// Main page
+	{Key: "carousel", Name: "Carousel slide", LabelColumn: "title", New: func() interface{} { return &Carousel{} }, NewSlice: func() interface{} { return &[]Carousel{} }},
+	{Key: "why-visit", Name: "Why visit item", LabelColumn: "title", New: func() interface{} { return &WhyVisit{} }, NewSlice: func() interface{} { return &[]WhyVisit{} }},
+	{Key: "selling-points", Name: "Selling point", LabelColumn: "title", New: func() interface{} { return &SellingPoint{} }, NewSlice: func() interface{} { return &[]SellingPoint{} }},
+	{Key: "attractions", Name: "Attraction", LabelColumn: "title", New: func() interface{} { return &Attraction{} }, NewSlice: func() interface{} { return &[]Attraction{} }},
+	{Key: "pricing", Name: "Pricing", LabelColumn: "title", New: func() interface{} { return &Pricing{} }, NewSlice: func() interface{} { return &[]Pricing{} }},
+
+	// Destinations
+	{Key: "destinations", Name: "Destination", LabelColumn: "title", New: func() interface{} { return &Destination{} }, NewSlice: func() interface{} { return &[]Destination{} }},
+	{Key: "destination-categories", Name: "Destination category", LabelColumn: "name", New: func() interface{} { return &DestinationCategory{} }, NewSlice: func() interface{} { return &[]DestinationCategory{} }},
+
+	// Gallery
+	{Key: "gallery", Name: "Gallery image", LabelColumn: "title", New: func() interface{} { return &GalleryImage{} }, NewSlice: func() interface{} { return &[]GalleryImage{} }},
+	{Key: "gallery-categories", Name: "Gallery category", LabelColumn: "name", New: func() interface{} { return &GalleryCategory{} }, NewSlice: func() interface{} { return &[]GalleryCategory{} }},
+
+	// Regulations
+	{Key: "regulations", Name: "Regulation", LabelColumn: "question", New: func() interface{} { return &Regulation{} }, NewSlice: func() interface{} { return &[]Regulation{} }},
+	{Key: "regulation-categories", Name: "Regulation category", LabelColumn: "name", New: func() interface{} { return &RegulationCategory{} }, NewSlice: func() interface{} { return &[]RegulationCategory{} }},
+
+	// Facilities
+	{Key: "facilities", Name: "Facility", LabelColumn: "name", New: func() interface{} { return &Facility{} }, NewSlice: func() interface{} { return &[]Facility{} }},
+	{Key: "facility-categories", Name: "Facility category", LabelColumn: "name", New: func() interface{} { return &FacilityCategory{} }, NewSlice: func() interface{} { return &[]FacilityCategory{} }},
+
+	// News
+	{Key: "news", Name: "News article", LabelColumn: "title", New: func() interface{} { return &NewsArticle{} }, NewSlice: func() interface{} { return &[]NewsArticle{} }},
+	{Key: "news-categories", Name: "News category", LabelColumn: "name", New: func() interface{} { return &NewsCategory{} }, NewSlice: func() interface{} { return &[]NewsCategory{} }},
+	{Key: "news-authors", Name: "News author", LabelColumn: "name", New: func() interface{} { return &NewsAuthor{} }, NewSlice: func() interface{} { return &[]NewsAuthor{} }},
+
+	// Heritage
+	{Key: "heritage", Name: "Heritage", LabelColumn: "title", New: func() interface{} { return &Heritage{} }, NewSlice: func() interface{} { return &[]Heritage{} }},
+
+	// Page content singletons
+	{Key: "why-visit-content", Name: "Why visit content", Singleton: true, New: func() interface{} { return &GeneralWhyVisitContent{} }, NewSlice: func() interface{} { return &[]GeneralWhyVisitContent{} }},
+	{Key: "attraction-content", Name: "Attraction content", Singleton: true, New: func() interface{} { return &GeneralAttractionContent{} }, NewSlice: func() interface{} { return &[]GeneralAttractionContent{} }},
+	{Key: "pricing-content", Name: "Pricing content", Singleton: true, New: func() interface{} { return &GeneralPricingContent{} }, NewSlice: func() interface{} { return &[]GeneralPricingContent{} }},
+	{Key: "profile", Name: "Profile page content", Singleton: true, New: func() interface{} { return &ProfilePageContent{} }, NewSlice: func() interface{} { return &[]ProfilePageContent{} }},
+	{Key: "destination-page-content", Name: "Destination page content", Singleton: true, New: func() interface{} { return &DestinationPageContent{} }, NewSlice: func() interface{} { return &[]DestinationPageContent{} }},
+	{Key: "gallery-page-content", Name: "Gallery page content", Singleton: true, New: func() interface{} { return &GalleryPageContent{} }, NewSlice: func() interface{} { return &[]GalleryPageContent{} }},
+	{Key: "regulation-page-content", Name: "Regulation page content", Singleton: true, New: func() interface{} { return &RegulationPageContent{} }, NewSlice: func() interface{} { return &[]RegulationPageContent{} }},
+	{Key: "facility-page-content", Name: "Facility page content", Singleton: true, New: func() interface{} { return &FacilityPageContent{} }, NewSlice: func() interface{} { return &[]FacilityPageContent{} }},
+	{Key: "news-page-content", Name: "News page content", Singleton: true, New: func() interface{} { return &NewsPageContent{} }, NewSlice: func() interface{} { return &[]NewsPageContent{} }},
+	{Key: "heritage-page-content", Name: "Heritage page content", Singleton: true, New: func() interface{} { return &HeritagePageContent{} }, NewSlice: func() interface{} { return &[]HeritagePageContent{} }},
+	{Key: "contact-info", Name: "Contact info", Singleton: true, New: func() interface{} { return &ContactInfo{} }, NewSlice: func() interface{} { return &[]ContactInfo{} }},
+	{Key: "contact-content", Name: "Contact content", Singleton: true, New: func() interface{} { return &ContactContent{} }, NewSlice: func() interface{} { return &[]ContactContent{} }},
+}
+
+// patchableFields whitelists the JSON fields PATCH may change per content type. Publication
+// state, slugs, ordering, featured flags and categories have endpoints of their own and are
+// left out, as are IDs, timestamps and versions.
+var patchableFields = map[string][]string{
+	// Main page
+	"carousel":       {"title", "title_id", "subtitle", "subtitle_id", "image_url", "thumbnail_url", "alt_text", "alt_text_id"},
+	"why-visit":      {"title", "title_id", "description", "description_id", "icon_url"},
+	"selling-points": {"title", "title_id", "description", "description_id", "image_url", "thumbnail_url", "pillar_color", "text_color"},
+	"attractions":    {"title", "title_id", "subtitle", "subtitle_id", "description", "description_id", "highlights", "highlights_id", "image_url"},
+	"pricing": {"title", "title_id", "subtitle", "subtitle_id", "description", "adult_price", "infant_price", "currency",
+		"color", "start_gradient_color", "end_gradient_color", "image_url", "thumbnail_url"},
+
+	// Destinations
+	"destinations": {"title", "title_id", "short_description", "short_description_id", "about", "about_id", "highlights", "highlights_id",
+		"image_url", "thumbnail_url", "cta_url", "google_maps_url", "destination_detail_sections"},
+	"destination-categories": {"name", "name_id", "description", "description_id"},
+
+	// Gallery
+	"gallery": {"title", "title_id", "short_description", "short_description_id", "description", "description_id", "location",
+		"photographer", "date_uploaded", "tags", "tags_id", "image_url", "thumbnail_url", "media_type", "video_url", "duration_seconds"},
+	"gallery-categories": {"name", "name_id", "description", "description_id"},
+
+	// Regulations
+	"regulations":           {"question", "question_id", "answer", "answer_id"},
+	"regulation-categories": {"name", "name_id", "description", "description_id"},
+
+	// Facilities
+	"facilities": {"name", "name_id", "short_description", "short_description_id", "description", "description_id", "highlights", "highlights_id",
+		"capacity", "capacity_id", "duration", "duration_id", "price", "price_id", "image_url", "thumbnail_url", "cta_url", "facility_detail_sections"},
+	"facility-categories": {"name", "name_id", "description", "description_id"},
+
+	// News
+	"news": {"title", "title_id", "excerpt", "excerpt_id", "content", "content_id", "image_url", "author_id", "date_published",
+		"read_time", "tags"},
+	"news-categories": {"name", "name_id", "description", "description_id"},
+	"news-authors":    {"name", "avatar"},
+
+	// Heritage
+	"heritage": {"title", "title_id", "short_description", "short_description_id", "description", "description_id", "image_url",
+		"thumbnail_url", "heritage_detail_sections"},
+
+	// Page content singletons
+	"why-visit-content": {"why_visit_section_title_part_1", "why_visit_section_title_part_1_id", "why_visit_section_title_part_2",
+		"why_visit_section_title_part_2_id", "why_visit_section_description", "why_visit_section_description_id"},
+	"attraction-content": {"attraction_section_title_part_1", "attraction_section_title_part_1_id", "attraction_section_title_part_2",
+		"attraction_section_title_part_2_id", "attraction_section_description", "attraction_section_description_id"},
+	"pricing-content": {"general_pricing_section_title_part_1", "general_pricing_section_title_part_1_id", "general_pricing_section_title_part_2",
+		"general_pricing_section_title_part_2_id", "general_pricing_section_description", "general_pricing_section_description_id"},
+	"profile": {"title", "title_id", "subtitle", "subtitle_id", "header_image_url", "brief_section_title", "brief_section_title_id",
+		"brief_section_content", "brief_section_content_id", "brief_section_image_url", "profile_sections", "cta_section_title",
+		"cta_section_title_id", "cta_section_text", "cta_section_text_id", "cta_section_button_text", "cta_section_button_text_id",
+		"cta_section_button_url"},
+	"destination-page-content": {"title", "title_id", "subtitle", "subtitle_id", "hero_image_url", "hero_image_thumbnail_url",
+		"featured_destination_title", "featured_destination_title_id", "featured_destination_description",
+		"featured_destination_description_id", "other_destinations_title", "other_destinations_title_id",
+		"other_destinations_description", "other_destinations_description_id", "cta_title", "cta_title_id", "cta_description",
+		"cta_description_id", "cta_button_text", "cta_button_text_id", "cta_button_url"},
+	"gallery-page-content": {"title", "title_id", "subtitle", "subtitle_id", "hero_image_url", "hero_image_thumbnail_url"},
+	"regulation-page-content": {"title", "title_id", "subtitle", "subtitle_id", "hero_image_url", "hero_image_thumbnail_url",
+		"cta_title", "cta_title_id", "cta_description", "cta_description_id", "cta_button_text", "cta_button_text_id", "cta_button_url"},
+	"facility-page-content": {"title", "title_id", "subtitle", "subtitle_id", "hero_image_url", "hero_image_thumbnail_url",
+		"facilities_list_section_title", "facilities_list_section_title_id", "facilities_list_section_description",
+		"facilities_list_section_description_id", "cta_title", "cta_title_id", "cta_description", "cta_description_id",
+		"cta_button_text", "cta_button_text_id", "cta_button_url"},
+	"news-page-content": {"title", "title_id", "subtitle", "subtitle_id", "hero_image_url", "hero_image_thumbnail_url",
+		"highlight_section_title", "highlight_section_title_id"},
+	"heritage-page-content": {"title", "title_id", "subtitle", "subtitle_id", "hero_image_url", "hero_image_thumbnail_url",
+		"main_section_title", "main_section_title_id", "main_section_description", "main_section_description_id", "cta_title",
+		"cta_title_id", "cta_description", "cta_description_id", "cta_button_text", "cta_button_text_id", "cta_button_url"},
+	"contact-info": {"address_part_1", "address_part_1_id", "address_part_2", "address_part_2_id", "phones", "emails",
+		"social_media", "plan_your_visit_url", "latitude", "longitude"},
+	"contact-content": {"contact_section_title_part_1", "contact_section_title_part_1_id", "contact_section_title_part_2",
+		"contact_section_title_part_2_id", "contact_section_description", "contact_section_description_id"},
+}
+
+// PatchableFields returns the JSON fields of a content type that PATCH may change
+func PatchableFields(key string) []string {
+	return patchableFields[key]
+}
+
+// ContentTypes returns all registered content types
+func ContentTypes() []ContentType {
+	return contentTypes
+}
+
+// LookupContentType finds a registered content type by key
+func LookupContentType(key string) (ContentType, bool) {
+	for _, ct := range contentTypes {
+		if ct.Key == key {
+			return ct, true
+		}
+	}
+	return ContentType{}, false
+}
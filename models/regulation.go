@@ -31,6 +31,7 @@ type RegulationCategory struct {
 
 type Regulation struct {
 	BaseModel
+	Publication
 	CategoryID         uint               `json:"category_id"`
 	RegulationCategory RegulationCategory `json:"regulation_category" gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	Question           string             `json:"question" gorm:"not null"`
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Revision actions
+const (
+	RevisionActionBaseline = "baseline" // state captured before the first tracked update
+	RevisionActionUpdate   = "update"
+	RevisionActionRestore  = "restore"
+)
+
+// ContentRevision stores a full JSON snapshot of a content row after each change
+type ContentRevision struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	ContentType    string         `json:"content_type" gorm:"size:50;not null;index:idx_content_revisions_content;uniqueIndex:idx_content_revisions_version"`
+	ContentID      uint           `json:"content_id" gorm:"not null;index:idx_content_revisions_content;uniqueIndex:idx_content_revisions_version"`
+	Version        int            `json:"version" gorm:"not null;uniqueIndex:idx_content_revisions_version"`
+	Action         string         `json:"action" gorm:"size:20;not null"`
+	Snapshot       datatypes.JSON `json:"snapshot,omitempty" gorm:"type:jsonb;not null"`
+	AuthorID       *uint          `json:"author_id"`
+	AuthorUsername string         `json:"author_username"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+func (ContentRevision) TableName() string {
+	return "content_revisions"
+}
+
+// RenumberRevisions gives revisions that share a version number with another revision of the
+// same content consecutive numbers, so the unique index on (content_type, content_id, version)
+// can be created on databases that recorded them before it existed
+func RenumberRevisions(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&ContentRevision{}) || db.Migrator().HasIndex(&ContentRevision{}, "idx_content_revisions_version") {
+		return nil
+	}
+	return db.Exec(`
+		UPDATE content_revisions AS r SET version = n.position
+		FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY content_type, content_id ORDER BY version, id) AS position
+			FROM content_revisions
+		) AS n
+		WHERE r.id = n.id AND r.version <> n.position`).Error
+}
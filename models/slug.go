@@ -0,0 +1,334 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"yaro-wora-be/utils"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Slug languages
+const (
+	SlugLanguageEN = "en"
+	SlugLanguageID = "id"
+)
+
+// Slugs holds the human-readable URL identifiers of a content item per language.
+// Slugs are generated from the titles when empty and may be edited by admins.
+type Slugs struct {
+	Slug   string `json:"slug" gorm:"size:255"`    // Unique among live rows, see EnsureUniqueSlugs
+	SlugID string `json:"slug_id" gorm:"size:255"` // Unique among live rows, see EnsureUniqueSlugs
+}
+
+// SlugRedirect keeps an old slug resolving after the content it belonged to got a new one
+type SlugRedirect struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ContentType string    `json:"content_type" gorm:"size:50;not null;uniqueIndex:idx_slug_redirects_slug"`
+	OldSlug     string    `json:"old_slug" gorm:"size:255;not null;uniqueIndex:idx_slug_redirects_slug"`
+	Language    string    `json:"language" gorm:"size:2;not null"`
+	ContentID   uint      `json:"content_id" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (SlugRedirect) TableName() string {
+	return "slug_redirects"
+}
+
+// sluggableTable describes where a content type keeps its titles and slugs
+type sluggableTable struct {
+	Table         string
+	TitleColumn   string
+	TitleIDColumn string
+}
+
+var sluggableTables = map[string]sluggableTable{
+	"destinations": {"destinations", "title", "title_id"},
+	"facilities":   {"facilities", "name", "name_id"},
+	"gallery":      {"gallery_images", "title", "title_id"},
+	"news":         {"news_articles", "title", "title_id"},
+	"heritage":     {"heritages", "title", "title_id"},
+}
+
+// slugColumns are the columns of a sluggable table that get a unique index
+var slugColumns = []string{"slug", "slug_id"}
+
+// slugConflictAttempts is how often a save that lost a race for a slug is tried
+const slugConflictAttempts = 3
+
+// sluggable is implemented by models that embed Slugs
+type sluggable interface {
+	assignSlugs(tx *gorm.DB) error
+}
+
+// assign fills in and de-duplicates the slugs of a row before it is saved. When the title changed
+// and the slug was not edited along with it, the slug is regenerated from the new title.
+// Replaced slugs are kept in the redirect table.
+func (s *Slugs) assign(tx *gorm.DB, contentType string, id uint, title, titleID string) error {
+	info := sluggableTables[contentType]
+
+	// Partial updates (e.g. Model(&Destination{}).Update(...)) carry no title; leave slugs alone
+	if id != 0 && strings.TrimSpace(title) == "" {
+		return nil
+	}
+	if strings.TrimSpace(titleID) == "" {
+		titleID = title
+	}
+
+	var previous struct {
+		Slug    string
+		SlugID  string
+		Title   string
+		TitleID string
+	}
+	if id != 0 {
+		if err := tx.Unscoped().Table(info.Table).
+			Select(fmt.Sprintf("slug, slug_id, %s AS title, %s AS title_id", info.TitleColumn, info.TitleIDColumn)).
+			Where("id = ?", id).
+			Scan(&previous).Error; err != nil {
+			return err
+		}
+	}
+
+	if s.Slug == previous.Slug && title != previous.Title {
+		s.Slug = ""
+	}
+	if s.SlugID == previous.SlugID && titleID != previous.TitleID {
+		s.SlugID = ""
+	}
+
+	var err error
+	if s.Slug, err = uniqueSlug(tx, info, id, s.Slug, title); err != nil {
+		return err
+	}
+	if s.SlugID, err = uniqueSlug(tx, info, id, s.SlugID, titleID); err != nil {
+		return err
+	}
+
+	// The new slugs belong to this row now; drop redirects that would shadow them
+	if err := tx.Where("content_type = ? AND old_slug IN ?", contentType, []string{s.Slug, s.SlugID}).
+		Delete(&SlugRedirect{}).Error; err != nil {
+		return err
+	}
+
+	redirects := []SlugRedirect{}
+	if previous.Slug != "" && previous.Slug != s.Slug && previous.Slug != s.SlugID {
+		redirects = append(redirects, SlugRedirect{ContentType: contentType, OldSlug: previous.Slug, Language: SlugLanguageEN, ContentID: id})
+	}
+	if previous.SlugID != "" && previous.SlugID != s.SlugID && previous.SlugID != s.Slug && previous.SlugID != previous.Slug {
+		redirects = append(redirects, SlugRedirect{ContentType: contentType, OldSlug: previous.SlugID, Language: SlugLanguageID, ContentID: id})
+	}
+	if len(redirects) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "content_type"}, {Name: "old_slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"language", "content_id"}),
+	}).Create(&redirects).Error
+}
+
+// uniqueSlug normalizes the requested slug (or derives one from the title) and appends
+// a counter until no other row of the table uses it in either language
+func uniqueSlug(tx *gorm.DB, info sluggableTable, id uint, requested, title string) (string, error) {
+	return firstFreeSlug(slugBase(requested, title), func(candidate string) (bool, error) {
+		var count int64
+		err := tx.Unscoped().Table(info.Table).
+			Where("(slug = ? OR slug_id = ?) AND id <> ?", candidate, candidate, id).
+			Count(&count).Error
+		return count > 0, err
+	})
+}
+
+// slugBase returns the slug to start from: the requested one, else one derived from the title
+func slugBase(requested, title string) string {
+	base := utils.Slugify(requested)
+	if base == "" {
+		base = utils.Slugify(title)
+	}
+	if base == "" {
+		base = "untitled"
+	}
+	// Purely numeric slugs would be mistaken for IDs
+	if _, err := strconv.Atoi(base); err == nil {
+		base = "item-" + base
+	}
+	return base
+}
+
+// firstFreeSlug returns base, or base with the lowest counter from 2 up that isn't taken
+func firstFreeSlug(base string, taken func(candidate string) (bool, error)) (string, error) {
+	candidate := base
+	for n := 2; ; n++ {
+		used, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// ResolveSlug finds the ID of the content a slug refers to. For slugs that were replaced,
+// canonical holds the current slug in the same language so callers can redirect to it; visible
+// limits the content an old slug may redirect to, so it doesn't reveal unpublished content.
+// Returns gorm.ErrRecordNotFound when nothing matches.
+func ResolveSlug(db *gorm.DB, contentType, slug string, visible func(*gorm.DB) *gorm.DB) (id uint, canonical string, err error) {
+	info, ok := sluggableTables[contentType]
+	if !ok {
+		return 0, "", gorm.ErrRecordNotFound
+	}
+
+	var current struct {
+		ID     uint
+		Slug   string
+		SlugID string
+	}
+	if err := db.Table(info.Table).
+		Select("id, slug, slug_id").
+		Where("deleted_at IS NULL AND (slug = ? OR slug_id = ?)", slug, slug).
+		Limit(1).
+		Scan(&current).Error; err != nil {
+		return 0, "", err
+	}
+	if current.ID != 0 {
+		return current.ID, "", nil
+	}
+
+	var redirect SlugRedirect
+	if err := db.Where("content_type = ? AND old_slug = ?", contentType, slug).First(&redirect).Error; err != nil {
+		return 0, "", err
+	}
+	if err := db.Table(info.Table).
+		Scopes(visible).
+		Select("id, slug, slug_id").
+		Where("deleted_at IS NULL AND id = ?", redirect.ContentID).
+		Scan(&current).Error; err != nil {
+		return 0, "", err
+	}
+	if current.ID == 0 {
+		return 0, "", gorm.ErrRecordNotFound
+	}
+
+	canonical = current.Slug
+	if redirect.Language == SlugLanguageID && current.SlugID != "" {
+		canonical = current.SlugID
+	}
+	return current.ID, canonical, nil
+}
+
+// slugIndexName is the name of the unique index on a slug column
+func slugIndexName(table, column string) string {
+	return "idx_" + table + "_" + column + "_unique"
+}
+
+// IsSlugConflict reports whether err is a violation of one of the unique slug indexes, which
+// happens when a concurrent save took the slug between picking and saving it
+func IsSlugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return false
+	}
+	for _, info := range sluggableTables {
+		for _, column := range slugColumns {
+			if pgErr.ConstraintName == slugIndexName(info.Table, column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RetryOnSlugConflict runs save again when it failed on a slug another save took first. The
+// slug hooks then see the other row and pick the next free slug. save must run in a
+// transaction (or savepoint) of its own, as the failed statement aborts it.
+func RetryOnSlugConflict(save func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := save()
+		if err == nil || attempt == slugConflictAttempts || !IsSlugConflict(err) {
+			return err
+		}
+	}
+}
+
+// EnsureUniqueSlugs gives live rows that share a slug a new one, generates missing slugs and
+// creates the unique indexes that keep slugs unique under concurrent saves
+func EnsureUniqueSlugs(db *gorm.DB) error {
+	for _, info := range sluggableTables {
+		for _, column := range slugColumns {
+			// Keep the slug on the oldest row; BackfillSlugs generates new ones for the others
+			if err := db.Exec(fmt.Sprintf(`
+				UPDATE %[1]s AS t SET %[2]s = ''
+				WHERE t.deleted_at IS NULL AND t.%[2]s <> '' AND EXISTS (
+					SELECT 1 FROM %[1]s AS o
+					WHERE o.deleted_at IS NULL AND o.%[2]s = t.%[2]s AND o.id < t.id
+				)`, info.Table, column)).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := BackfillSlugs(db); err != nil {
+		return err
+	}
+
+	for _, info := range sluggableTables {
+		for _, column := range slugColumns {
+			// Replaces the plain index slugs had before
+			if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS idx_%s_%s", info.Table, column)).Error; err != nil {
+				return err
+			}
+			if err := db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s) WHERE deleted_at IS NULL",
+				slugIndexName(info.Table, column), info.Table, column)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BackfillSlugs generates slugs for rows created before slugs existed
+func BackfillSlugs(db *gorm.DB) error {
+	for contentType := range sluggableTables {
+		ct, ok := LookupContentType(contentType)
+		if !ok {
+			return errors.New("unknown sluggable content type " + contentType)
+		}
+
+		rows := ct.NewSlice()
+		if err := db.Unscoped().Where("slug IS NULL OR slug = '' OR slug_id IS NULL OR slug_id = ''").Find(rows).Error; err != nil {
+			return err
+		}
+
+		items := reflect.ValueOf(rows).Elem()
+		for i := 0; i < items.Len(); i++ {
+			row := items.Index(i).Addr().Interface()
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				if err := row.(sluggable).assignSlugs(tx); err != nil {
+					return err
+				}
+				slugs := reflect.ValueOf(row).Elem().FieldByName("Slugs").Interface().(Slugs)
+				return tx.Unscoped().Model(row).UpdateColumns(map[string]interface{}{
+					"slug":    slugs.Slug,
+					"slug_id": slugs.SlugID,
+				}).Error
+			}); err != nil {
+				return err
+			}
+		}
+
+		if items.Len() > 0 {
+			log.Printf("Generated slugs for %d %s row(s)", items.Len(), contentType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSlugBase(t *testing.T) {
+	tests := []struct {
+		requested string
+		title     string
+		want      string
+	}{
+		{"", "Pantai Marosi Beach", "pantai-marosi-beach"},
+		{"My Custom Slug!", "Ignored title", "my-custom-slug"},
+		{"", "Desa Ratenggaro – Kampung Adat", "desa-ratenggaro-kampung-adat"},
+		{"", "Café Sumbà", "cafe-sumba"},
+		{"!!!", "Fallback Title", "fallback-title"},
+		{"", "", "untitled"},
+		{"", "!!!", "untitled"},
+		{"2024", "", "item-2024"},
+		{"", "42", "item-42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.requested+"|"+tt.title, func(t *testing.T) {
+			if got := slugBase(tt.requested, tt.title); got != tt.want {
+				t.Errorf("slugBase(%q, %q) = %q, want %q", tt.requested, tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstFreeSlug(t *testing.T) {
+	tests := []struct {
+		name  string
+		taken []string
+		want  string
+	}{
+		{"free", nil, "beach"},
+		{"taken", []string{"beach"}, "beach-2"},
+		{"counter taken too", []string{"beach", "beach-2", "beach-3"}, "beach-4"},
+		{"gap is reused", []string{"beach", "beach-3"}, "beach-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := map[string]bool{}
+			for _, slug := range tt.taken {
+				used[slug] = true
+			}
+			got, err := firstFreeSlug("beach", func(candidate string) (bool, error) {
+				return used[candidate], nil
+			})
+			if err != nil || got != tt.want {
+				t.Errorf("firstFreeSlug() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstFreeSlugError(t *testing.T) {
+	failure := errors.New("connection lost")
+	if _, err := firstFreeSlug("beach", func(string) (bool, error) { return false, failure }); !errors.Is(err, failure) {
+		t.Errorf("firstFreeSlug() error = %v, want %v", err, failure)
+	}
+}
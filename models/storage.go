@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+	"yaro-wora-be/utils"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StorageUsageSnapshot is the storage use of one day, for the usage growth chart. The row of
+// the current day is updated on every reconciliation, so it ends up holding the day's last total.
+type StorageUsageSnapshot struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	Date          time.Time      `json:"date" gorm:"type:date;not null;uniqueIndex"`
+	TotalBytes    int64          `json:"total_bytes"`
+	ObjectCount   int64          `json:"object_count"`
+	ByFolder      datatypes.JSON `json:"by_folder" gorm:"type:jsonb"`       // []utils.UsageTotal
+	ByContentType datatypes.JSON `json:"by_content_type" gorm:"type:jsonb"` // []utils.UsageTotal
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+func (StorageUsageSnapshot) TableName() string {
+	return "storage_usage_snapshots"
+}
+
+// RecordStorageUsage stores usage as the snapshot of the day it was taken on
+func RecordStorageUsage(db *gorm.DB, usage *utils.StorageUsage, takenAt time.Time) error {
+	byFolder, _ := json.Marshal(usage.ByFolder)
+	byContentType, _ := json.Marshal(usage.ByContentType)
+
+	snapshot := StorageUsageSnapshot{
+		Date:          time.Date(takenAt.Year(), takenAt.Month(), takenAt.Day(), 0, 0, 0, 0, takenAt.Location()),
+		TotalBytes:    usage.TotalBytes,
+		ObjectCount:   usage.ObjectCount,
+		ByFolder:      datatypes.JSON(byFolder),
+		ByContentType: datatypes.JSON(byContentType),
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"total_bytes", "object_count", "by_folder", "by_content_type", "updated_at"}),
+	}).Create(&snapshot).Error
+}
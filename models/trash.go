@@ -0,0 +1,108 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTrashItemInUse is returned when purging a row that other rows still reference
+var ErrTrashItemInUse = errors.New("item is still referenced by other content")
+
+// TrashedItem is a soft-deleted row as shown in the trash bin
+type TrashedItem struct {
+	ContentType string    `json:"content_type"`
+	ID          uint      `json:"id"`
+	Label       string    `json:"label"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+// trashReference is a column of another model that points at a trashable row.
+// The foreign keys cascade on delete, so purging a referenced row would wipe its dependents.
+type trashReference struct {
+	Model  interface{}
+	Column string
+}
+
+var trashReferences = map[string][]trashReference{
+	"destination-categories": {{&Destination{}, "category_id"}},
+	"gallery-categories":     {{&GalleryImage{}, "category_id"}},
+	"regulation-categories":  {{&Regulation{}, "category_id"}},
+	"facility-categories":    {{&Facility{}, "category_id"}},
+	"news-categories":        {{&NewsArticle{}, "category_id"}},
+	"news-authors":           {{&NewsArticle{}, "author_id"}},
+}
+
+// ListTrashed returns the soft-deleted rows of a content type, most recently deleted first
+func ListTrashed(db *gorm.DB, ct ContentType) ([]TrashedItem, error) {
+	var items []TrashedItem
+	err := db.Unscoped().
+		Model(ct.New()).
+		Select("id, deleted_at, " + ct.LabelColumn + " AS label").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].ContentType = ct.Key
+	}
+	return items, nil
+}
+
+// ExpiredTrashIDs returns the IDs of rows that were soft-deleted before the cutoff
+func ExpiredTrashIDs(db *gorm.DB, ct ContentType, cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	err := db.Unscoped().
+		Model(ct.New()).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// RestoreTrashed clears deleted_at on a soft-deleted row.
+// Returns gorm.ErrRecordNotFound when the row is not in the trash.
+func RestoreTrashed(db *gorm.DB, ct ContentType, id uint) error {
+	result := db.Session(&gorm.Session{SkipHooks: true}).
+		Unscoped().
+		Model(ct.New()).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeTrashed permanently deletes a soft-deleted row and returns it so the caller
+// can clean up files it referenced. Rows that are still referenced are not purged.
+func PurgeTrashed(db *gorm.DB, ct ContentType, id uint) (interface{}, error) {
+	row := ct.New()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(row).Error; err != nil {
+			return err
+		}
+
+		for _, ref := range trashReferences[ct.Key] {
+			var count int64
+			if err := tx.Unscoped().Model(ref.Model).Where(ref.Column+" = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrTrashItemInUse
+			}
+		}
+
+		return tx.Unscoped().Delete(row).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
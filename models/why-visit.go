@@ -2,6 +2,7 @@ package models
 
 type WhyVisit struct {
 	BaseModel
+	Publication
 	Title         string `json:"title" gorm:"not null"`
 	TitleID       string `json:"title_id" gorm:"not null"`
 	Description   string `json:"description"`
@@ -16,120 +16,211 @@ func SetupAdminRoutes(api fiber.Router) {
 	admin.Get("/profile", handlers.GetProfilePageContent)
 
 	// Main page management
+	admin.Get("/carousel", handlers.GetCarousel)
 	admin.Post("/carousel", handlers.CreateCarousel)
+	admin.Post("/carousel/bulk", handlers.BulkCarousel)
+	admin.Put("/carousel/order", handlers.ReorderCarousel)
 	admin.Put("/carousel/:id", handlers.UpdateCarousel)
+	admin.Patch("/carousel/:id", handlers.PatchContent("carousel"))
 	admin.Delete("/carousel/:id", handlers.DeleteCarousel)
 
+	admin.Get("/why-visit", handlers.GetWhyVisit)
 	admin.Post("/why-visit", handlers.CreateWhyVisit)
+	admin.Post("/why-visit/bulk", handlers.BulkWhyVisit)
 	admin.Put("/why-visit/:id", handlers.UpdateWhyVisit)
+	admin.Patch("/why-visit/:id", handlers.PatchContent("why-visit"))
 	admin.Delete("/why-visit/:id", handlers.DeleteWhyVisit)
 
 	admin.Put("/why-visit-content", handlers.UpdateGeneralWhyVisitContent)
+	admin.Patch("/why-visit-content", handlers.PatchSingleton("why-visit-content"))
 
+	admin.Get("/selling-points", handlers.GetSellingPoints)
 	admin.Post("/selling-points", handlers.CreateSellingPoint)
+	admin.Post("/selling-points/bulk", handlers.BulkSellingPoints)
+	admin.Put("/selling-points/order", handlers.ReorderSellingPoints)
 	admin.Put("/selling-points/:id", handlers.UpdateSellingPoint)
+	admin.Patch("/selling-points/:id", handlers.PatchContent("selling-points"))
 	admin.Delete("/selling-points/:id", handlers.DeleteSellingPoint)
 
+	admin.Get("/attractions", handlers.GetAttractions)
 	admin.Post("/attractions", handlers.CreateAttraction)
+	admin.Post("/attractions/bulk", handlers.BulkAttractions)
+	admin.Put("/attractions/order", handlers.ReorderAttractions)
 	admin.Put("/attractions/:id", handlers.UpdateAttraction)
+	admin.Patch("/attractions/:id", handlers.PatchContent("attractions"))
 	admin.Delete("/attractions/:id", handlers.DeleteAttraction)
 
 	admin.Put("/attraction-content", handlers.UpdateGeneralAttractionContent)
+	admin.Patch("/attraction-content", handlers.PatchSingleton("attraction-content"))
 
 	admin.Put("/pricing", handlers.UpdatePricing)
+	admin.Patch("/pricing/:id", handlers.PatchContent("pricing"))
 
 	admin.Put("/pricing-content", handlers.UpdateGeneralPricingContent)
+	admin.Patch("/pricing-content", handlers.PatchSingleton("pricing-content"))
 
 	// Profile page management
 	admin.Put("/profile", handlers.UpdateProfilePageContent)
+	admin.Patch("/profile", handlers.PatchSingleton("profile"))
 
 	// Destination page content management
 	admin.Put("/destinations/content", handlers.UpdateDestinationPageContent)
+	admin.Patch("/destinations/content", handlers.PatchSingleton("destination-page-content"))
 
-	// Destinations management
+	// Destinations management (admin listing includes drafts, scheduled and archived items)
+	admin.Get("/destinations", handlers.GetDestinations)
+	admin.Get("/destinations/:id", handlers.GetDestinationByID)
 	admin.Post("/destinations", handlers.CreateDestination)
+	admin.Post("/destinations/bulk", handlers.BulkDestinations)
+	admin.Put("/destinations/order", handlers.ReorderDestinations)
 	admin.Put("/destinations/:id", handlers.UpdateDestination)
+	admin.Patch("/destinations/:id", handlers.PatchContent("destinations"))
 	admin.Delete("/destinations/:id", handlers.DeleteDestination)
 
 	// Destination categories management
 	admin.Post("/destinations/categories", handlers.CreateDestinationCategory)
 	admin.Put("/destinations/categories/:id", handlers.UpdateDestinationCategory)
+	admin.Patch("/destinations/categories/:id", handlers.PatchContent("destination-categories"))
 	admin.Delete("/destinations/categories/:id", handlers.DeleteDestinationCategory)
 
 	// Gallery page content management
 	admin.Put("/gallery/content", handlers.UpdateGalleryPageContent)
+	admin.Patch("/gallery/content", handlers.PatchSingleton("gallery-page-content"))
 
 	// Destinations management
+	admin.Get("/gallery", handlers.GetGallery)
+	admin.Get("/gallery/:id", handlers.GetGalleryImageByID)
 	admin.Post("/gallery", handlers.CreateGalleryImage)
+	admin.Post("/gallery/import", handlers.ImportGallerySpreadsheet)
+	admin.Post("/gallery/bulk", handlers.BulkGallery)
 	admin.Put("/gallery/:id", handlers.UpdateGalleryImage)
+	admin.Patch("/gallery/:id", handlers.PatchContent("gallery"))
 	admin.Delete("/gallery/:id", handlers.DeleteGalleryImage)
 
 	// Gallery categories management
 	admin.Post("/gallery/categories", handlers.CreateGalleryCategory)
 	admin.Put("/gallery/categories/:id", handlers.UpdateGalleryCategory)
+	admin.Patch("/gallery/categories/:id", handlers.PatchContent("gallery-categories"))
 	admin.Delete("/gallery/categories/:id", handlers.DeleteGalleryCategory)
 
 	// Regulation page content management
 	admin.Put("/regulations/content", handlers.UpdateRegulationPageContent)
+	admin.Patch("/regulations/content", handlers.PatchSingleton("regulation-page-content"))
 
 	// Regulations management
+	admin.Get("/regulations", handlers.GetRegulations)
+	admin.Get("/regulations/:id", handlers.GetRegulationByID)
 	admin.Post("/regulations", handlers.CreateRegulation)
+	admin.Post("/regulations/import", handlers.ImportRegulationsSpreadsheet)
+	admin.Post("/regulations/bulk", handlers.BulkRegulations)
 	admin.Put("/regulations/:id", handlers.UpdateRegulation)
+	admin.Patch("/regulations/:id", handlers.PatchContent("regulations"))
 	admin.Delete("/regulations/:id", handlers.DeleteRegulation)
 
 	// Regulation categories management
 	admin.Post("/regulations/categories", handlers.CreateRegulationCategory)
 	admin.Put("/regulations/categories/:id", handlers.UpdateRegulationCategory)
+	admin.Patch("/regulations/categories/:id", handlers.PatchContent("regulation-categories"))
 	admin.Delete("/regulations/categories/:id", handlers.DeleteRegulationCategory)
 
 	// Facilities page content management
 	admin.Put("/facilities/content", handlers.UpdateFacilityPageContent)
+	admin.Patch("/facilities/content", handlers.PatchSingleton("facility-page-content"))
 
 	// Facilities management
+	admin.Get("/facilities", handlers.GetFacilities)
+	admin.Get("/facilities/:id", handlers.GetFacilityByID)
 	admin.Post("/facilities", handlers.CreateFacility)
+	admin.Post("/facilities/bulk", handlers.BulkFacilities)
+	admin.Put("/facilities/order", handlers.ReorderFacilities)
 	admin.Put("/facilities/:id", handlers.UpdateFacility)
+	admin.Patch("/facilities/:id", handlers.PatchContent("facilities"))
 	admin.Delete("/facilities/:id", handlers.DeleteFacility)
 
 	// Facility categories management
 	admin.Post("/facilities/categories", handlers.CreateFacilityCategory)
 	admin.Put("/facilities/categories/:id", handlers.UpdateFacilityCategory)
+	admin.Patch("/facilities/categories/:id", handlers.PatchContent("facility-categories"))
 	admin.Delete("/facilities/categories/:id", handlers.DeleteFacilityCategory)
 
 	// News page content management
 	admin.Put("/news/content", handlers.UpdateNewsPageContent)
+	admin.Patch("/news/content", handlers.PatchSingleton("news-page-content"))
 
 	// News management
+	admin.Get("/news", handlers.GetNews)
+	admin.Get("/news/:id", handlers.GetNewsByID)
 	admin.Post("/news", handlers.CreateNews)
+	admin.Post("/news/bulk", handlers.BulkNews)
 	admin.Put("/news/:id", handlers.UpdateNews)
+	admin.Patch("/news/:id", handlers.PatchContent("news"))
 	admin.Delete("/news/:id", handlers.DeleteNews)
 
 	// News categories management
 	admin.Post("/news/categories", handlers.CreateNewsCategory)
 	admin.Put("/news/categories/:id", handlers.UpdateNewsCategory)
+	admin.Patch("/news/categories/:id", handlers.PatchContent("news-categories"))
 	admin.Delete("/news/categories/:id", handlers.DeleteNewsCategory)
 
 	// News authors management
 	admin.Post("/news/authors", handlers.CreateNewsAuthor)
 	admin.Put("/news/authors/:id", handlers.UpdateNewsAuthor)
+	admin.Patch("/news/authors/:id", handlers.PatchContent("news-authors"))
 	admin.Delete("/news/authors/:id", handlers.DeleteNewsAuthor)
 
 	// Contact management
 	admin.Put("/contact-info", handlers.UpdateContactInfo)
+	admin.Patch("/contact-info", handlers.PatchSingleton("contact-info"))
 	admin.Put("/contact-content", handlers.UpdateContactContent)
+	admin.Patch("/contact-content", handlers.PatchSingleton("contact-content"))
 
 	// Content management
 	admin.Post("/content/upload", handlers.UploadContent)
 
 	// Heritage page content management
 	admin.Put("/heritage/content", handlers.UpdateHeritagePageContent)
+	admin.Patch("/heritage/content", handlers.PatchSingleton("heritage-page-content"))
 
 	// Heritage management
+	admin.Get("/heritage", handlers.GetHeritage)
+	admin.Get("/heritage/:id", handlers.GetHeritageByID)
 	admin.Post("/heritage", handlers.CreateHeritage)
+	admin.Post("/heritage/bulk", handlers.BulkHeritage)
+	admin.Put("/heritage/order", handlers.ReorderHeritage)
 	admin.Put("/heritage/:id", handlers.UpdateHeritage)
+	admin.Patch("/heritage/:id", handlers.PatchContent("heritage"))
 	admin.Delete("/heritage/:id", handlers.DeleteHeritage)
 
+	// Preview links for unpublished content
+	admin.Post("/preview/:type/:id", handlers.CreatePreviewLink)
+
+	// Revision history (type is a content type key, e.g. destinations, news, profile)
+	admin.Get("/revisions/:type/:id", handlers.GetRevisions)
+	admin.Get("/revisions/:type/:id/diff", handlers.DiffRevisions)
+	admin.Get("/revisions/:type/:id/:revisionId", handlers.GetRevision)
+	admin.Post("/revisions/:type/:id/:revisionId/restore", handlers.RestoreRevision)
+
+	// Trash bin (soft-deleted content)
+	admin.Get("/trash", handlers.GetTrash)
+	admin.Post("/trash/:type/:id/restore", handlers.RestoreTrashItem)
+	admin.Delete("/trash/:type/:id", handlers.PurgeTrashItem)
+
+	// Media library
+	admin.Get("/media", handlers.GetMedia)
+	admin.Post("/media/gc", handlers.CollectOrphanedMedia)
+	admin.Get("/media/duplicates", handlers.FindDuplicateMedia)
+	admin.Post("/media/uploads", handlers.CreateMediaUpload)
+	admin.Post("/media/uploads/:id/complete", handlers.CompleteMediaUpload)
+	admin.Get("/media/:id", handlers.GetMediaAsset)
+	admin.Put("/media/:id", handlers.UpdateMediaAsset)
+
+	// Content bundles (move content between environments; importing is super admin only)
+	admin.Get("/bundle/export", handlers.ExportBundle)
+	admin.Post("/bundle/import", middleware.SuperAdminOnly(), handlers.ImportBundle)
+
 	// Analytics & Reports
 	admin.Get("/analytics/storage", handlers.GetStorageAnalytics)
+	admin.Get("/analytics/storage/history", handlers.GetStorageUsageHistory)
 	admin.Get("/analytics/visitors", handlers.GetVisitorAnalytics)
 	admin.Get("/analytics/bookings", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"message": "Booking analytics - TODO"})
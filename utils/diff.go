@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FieldChange represents a single changed field between two JSON documents
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffJSON returns field-level differences between two JSON objects.
+// Nested objects and arrays (e.g. detail sections) are compared element by element
+// and reported with paths like "destination_detail_sections[0].title".
+// Fields listed in ignore are skipped at the top level.
+func DiffJSON(from, to []byte, ignore ...string) ([]FieldChange, error) {
+	var a, b interface{}
+	if err := json.Unmarshal(from, &a); err != nil {
+		return nil, fmt.Errorf("invalid source document: %v", err)
+	}
+	if err := json.Unmarshal(to, &b); err != nil {
+		return nil, fmt.Errorf("invalid target document: %v", err)
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, field := range ignore {
+		skip[field] = true
+	}
+
+	changes := make([]FieldChange, 0)
+	diffValues("", a, b, skip, &changes)
+	return changes, nil
+}
+
+func diffValues(path string, a, b interface{}, skip map[string]bool, changes *[]FieldChange) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		// Collect keys from both sides in a stable order
+		keySet := make(map[string]bool)
+		for k := range av {
+			keySet[k] = true
+		}
+		for k := range bv {
+			keySet[k] = true
+		}
+		keys := make([]string, 0, len(keySet))
+		for k := range keySet {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if path == "" && skip[k] {
+				continue
+			}
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			diffValues(childPath, av[k], bv[k], skip, changes)
+		}
+		return
+
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+
+		n := len(av)
+		if len(bv) > n {
+			n = len(bv)
+		}
+		for i := 0; i < n; i++ {
+			var ai, bi interface{}
+			if i < len(av) {
+				ai = av[i]
+			}
+			if i < len(bv) {
+				bi = bv[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), ai, bi, skip, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, FieldChange{Field: path, From: a, To: b})
+	}
+}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/disintegration/imaging"
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// ImageExif is the capture data read from a photo's EXIF metadata. It is kept in the media
+// library only; published files are stripped of all metadata.
+type ImageExif struct {
+	CapturedAt  *time.Time `json:"captured_at,omitempty"`
+	CameraMake  string     `json:"camera_make,omitempty"`
+	CameraModel string     `json:"camera_model,omitempty"`
+	Artist      string     `json:"artist,omitempty"`
+	Latitude    *float64   `json:"latitude,omitempty"`
+	Longitude   *float64   `json:"longitude,omitempty"`
+
+	orientation int // 1-8, how the camera was held; applied to the pixels on upload
+}
+
+// Camera returns the make and model, e.g. "Apple iPhone 13"
+func (e *ImageExif) Camera() string {
+	// Models often repeat the make ("Canon" / "Canon EOS R6")
+	if strings.HasPrefix(strings.ToLower(e.CameraModel), strings.ToLower(e.CameraMake)) {
+		return e.CameraModel
+	}
+	return strings.TrimSpace(e.CameraMake + " " + e.CameraModel)
+}
+
+// HasCaptureData reports whether anything besides the orientation was found
+func (e *ImageExif) HasCaptureData() bool {
+	return e.CapturedAt != nil || e.CameraMake != "" || e.CameraModel != "" || e.Artist != "" || e.Latitude != nil
+}
+
+// Location returns the GPS position as "latitude, longitude", or "" without GPS data
+func (e *ImageExif) Location() string {
+	return FormatCoordinates(e.Latitude, e.Longitude)
+}
+
+// FormatCoordinates formats a GPS position as "-9.642300, 119.402700"
+func FormatCoordinates(latitude, longitude *float64) string {
+	if latitude == nil || longitude == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.6f, %.6f", *latitude, *longitude)
+}
+
+// readImageExif reads the EXIF metadata of a JPEG, PNG or WebP file. It returns nil when the
+// file has none or it can't be parsed; missing EXIF never fails an upload.
+func readImageExif(content []byte, format string) *ImageExif {
+	var block []byte
+	switch format {
+	case "jpeg":
+		block = content
+	case "png":
+		block = pngChunk(content, "eXIf")
+	case "webp":
+		block = webpChunk(content, "EXIF")
+	}
+	if len(block) == 0 {
+		return nil
+	}
+
+	x, err := exif.Decode(bytes.NewReader(block))
+	if err != nil && (x == nil || exif.IsCriticalError(err)) {
+		return nil
+	}
+
+	data := &ImageExif{orientation: 1}
+	if tag, err := x.Get(exif.Orientation); err == nil {
+		if orientation, err := tag.Int(0); err == nil && orientation >= 1 && orientation <= 8 {
+			data.orientation = orientation
+		}
+	}
+	if capturedAt, err := x.DateTime(); err == nil && !capturedAt.IsZero() {
+		data.CapturedAt = &capturedAt
+	}
+	data.CameraMake = exifString(x, exif.Make)
+	data.CameraModel = exifString(x, exif.Model)
+	data.Artist = exifString(x, exif.Artist)
+	if latitude, longitude, err := x.LatLong(); err == nil && (latitude != 0 || longitude != 0) {
+		data.Latitude = &latitude
+		data.Longitude = &longitude
+	}
+
+	return data
+}
+
+func exifString(x *exif.Exif, name exif.FieldName) string {
+	tag, err := x.Get(name)
+	if err != nil {
+		return ""
+	}
+	value, err := tag.StringVal()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(value, "\x00"))
+}
+
+// applyOrientation turns the pixels the way the camera was held, so the image displays
+// correctly without its EXIF orientation tag
+func applyOrientation(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	}
+	return img
+}
+
+// pngChunk returns the data of the first PNG chunk of the given type
+func pngChunk(content []byte, chunkType string) []byte {
+	const signatureLength = 8
+	for offset := signatureLength; offset+8 <= len(content); {
+		length := int(binary.BigEndian.Uint32(content[offset:]))
+		name := string(content[offset+4 : offset+8])
+		start, end := offset+8, offset+8+length
+		if length < 0 || end > len(content) {
+			return nil
+		}
+		if name == chunkType {
+			return content[start:end]
+		}
+		offset = end + 4 // CRC
+	}
+	return nil
+}
+
+// webpChunk returns the data of the first chunk of the given type in a WebP (RIFF) file
+func webpChunk(content []byte, chunkType string) []byte {
+	for _, chunk := range webpChunks(content) {
+		if chunk.name == chunkType {
+			return chunk.data
+		}
+	}
+	return nil
+}
+
+type riffChunk struct {
+	name string
+	data []byte
+}
+
+func webpChunks(content []byte) []riffChunk {
+	if len(content) < 12 || string(content[0:4]) != "RIFF" || string(content[8:12]) != "WEBP" {
+		return nil
+	}
+
+	var chunks []riffChunk
+	for offset := 12; offset+8 <= len(content); {
+		size := int(binary.LittleEndian.Uint32(content[offset+4:]))
+		start, end := offset+8, offset+8+size
+		if size < 0 || end > len(content) {
+			return nil
+		}
+		chunks = append(chunks, riffChunk{name: string(content[offset : offset+4]), data: content[start:end]})
+		offset = end + size%2 // Chunks are padded to an even size
+	}
+	return chunks
+}
+
+// stripWebPMetadata removes the EXIF and XMP chunks of a WebP file without re-encoding it.
+// Files that can't be parsed are returned as they are.
+func stripWebPMetadata(content []byte) []byte {
+	chunks := webpChunks(content)
+	if chunks == nil {
+		return content
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	for _, chunk := range chunks {
+		data := chunk.data
+		switch chunk.name {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			// Clear the EXIF (0x08) and XMP (0x04) flags of the extended header
+			data = append([]byte(nil), data...)
+			if len(data) > 0 {
+				data[0] &^= 0x08 | 0x04
+			}
+		}
+		body.WriteString(chunk.name)
+		binary.Write(&body, binary.LittleEndian, uint32(len(data)))
+		body.Write(data)
+		if len(data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// ImageCrop is a rectangle of an image in percent of its width and height
+type ImageCrop struct {
+	X      float64 `json:"x" validate:"gte=0,lte=100"`
+	Y      float64 `json:"y" validate:"gte=0,lte=100"`
+	Width  float64 `json:"width" validate:"gt=0,lte=100"`
+	Height float64 `json:"height" validate:"gt=0,lte=100"`
+}
+
+// Fits reports whether the rectangle lies within the image
+func (c ImageCrop) Fits() bool {
+	return c.X+c.Width <= 100 && c.Y+c.Height <= 100
+}
+
+// ImageFocus tells cropped variants which part of an image matters. The focal point is in
+// percent of the image (50/50 is the center); the optional crop limits cropping to a rectangle.
+type ImageFocus struct {
+	FocalX float64
+	FocalY float64
+	Crop   *ImageCrop
+}
+
+// CenterFocus keeps the center of an image, like a plain center crop
+var CenterFocus = ImageFocus{FocalX: 50, FocalY: 50}
+
+// fillFocused resizes and crops img to exactly width x height, keeping the focal point
+// as close to the center of the result as the image allows
+func fillFocused(img image.Image, width, height int, focus ImageFocus) image.Image {
+	bounds := img.Bounds()
+	focalX := float64(bounds.Dx()) * focus.FocalX / 100
+	focalY := float64(bounds.Dy()) * focus.FocalY / 100
+
+	if crop := focus.Crop; crop != nil {
+		rect := image.Rect(
+			int(math.Round(float64(bounds.Dx())*crop.X/100)),
+			int(math.Round(float64(bounds.Dy())*crop.Y/100)),
+			int(math.Round(float64(bounds.Dx())*(crop.X+crop.Width)/100)),
+			int(math.Round(float64(bounds.Dy())*(crop.Y+crop.Height)/100)),
+		).Add(bounds.Min).Intersect(bounds)
+		if !rect.Empty() {
+			img = imaging.Crop(img, rect)
+			focalX -= float64(rect.Min.X - bounds.Min.X)
+			focalY -= float64(rect.Min.Y - bounds.Min.Y)
+			bounds = img.Bounds()
+		}
+	}
+
+	// Scale so the image covers the target, then cut a window around the focal point
+	scale := math.Max(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	scaledWidth := int(math.Max(math.Round(float64(bounds.Dx())*scale), float64(width)))
+	scaledHeight := int(math.Max(math.Round(float64(bounds.Dy())*scale), float64(height)))
+	scaled := imaging.Resize(img, scaledWidth, scaledHeight, imaging.Lanczos)
+
+	left := clampInt(int(math.Round(focalX*scale))-width/2, 0, scaledWidth-width)
+	top := clampInt(int(math.Round(focalY*scale))-height/2, 0, scaledHeight-height)
+	return imaging.Crop(scaled, image.Rect(left, top, left+width, top+height))
+}
+
+// RegenerateCroppedVariants recreates the cropped variants of an uploaded image after its focus
+// changed, overwriting the previous files. Variants that keep the aspect ratio are not affected.
+func (s *StorageService) RegenerateCroppedVariants(key string, focus ImageFocus) (map[string]ImageVariant, error) {
+	var specs []ImageVariantSpec
+	for _, spec := range s.variants {
+		if spec.Crop() {
+			specs = append(specs, spec)
+		}
+	}
+	if len(specs) == 0 {
+		return map[string]ImageVariant{}, nil
+	}
+
+	content, err := s.backend.Get(context.TODO(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %v", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	return s.uploadVariantSpecs(img, key, specs, focus)
+}
+
+func clampInt(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+
+	"github.com/buckket/go-blurhash"
+	"github.com/chai2010/webp"
+	"github.com/disintegration/imaging"
+)
+
+// ImagePlaceholder is shown while an image loads: a BlurHash, a tiny inline image (LQIP)
+// and the dominant colour as a flat background
+type ImagePlaceholder struct {
+	BlurHash      string `json:"blurhash"`
+	LQIP          string `json:"lqip"`           // data:image/webp;base64,... about 20px on the longest side
+	DominantColor string `json:"dominant_color"` // #rrggbb
+}
+
+// computePlaceholder derives the placeholders of an image. Everything is computed
+// from a small copy, so it's cheap even for large uploads.
+func computePlaceholder(img image.Image) (*ImagePlaceholder, error) {
+	small := imaging.Fit(img, 64, 64, imaging.Box)
+
+	// 4 components along the longest side, 3 along the other
+	xComponents, yComponents := 4, 3
+	if bounds := small.Bounds(); bounds.Dy() > bounds.Dx() {
+		xComponents, yComponents = 3, 4
+	}
+	hash, err := blurhash.Encode(xComponents, yComponents, small)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute blurhash: %v", err)
+	}
+
+	var lqip bytes.Buffer
+	if err := webp.Encode(&lqip, imaging.Fit(small, 20, 20, imaging.Lanczos), &webp.Options{Quality: 40}); err != nil {
+		return nil, fmt.Errorf("failed to encode placeholder: %v", err)
+	}
+
+	return &ImagePlaceholder{
+		BlurHash:      hash,
+		LQIP:          "data:image/webp;base64," + base64.StdEncoding.EncodeToString(lqip.Bytes()),
+		DominantColor: dominantColor(small),
+	}, nil
+}
+
+// dominantColor returns the most common colour of an image as #rrggbb. Colours are grouped
+// in buckets of 16 shades per channel and the winning bucket is averaged; transparent
+// pixels are ignored.
+func dominantColor(img image.Image) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := map[int]*bucket{}
+	var best *bucket
+
+	nrgba := imaging.Clone(img)
+	for i := 0; i+3 < len(nrgba.Pix); i += 4 {
+		r, g, b, a := int(nrgba.Pix[i]), int(nrgba.Pix[i+1]), int(nrgba.Pix[i+2]), nrgba.Pix[i+3]
+		if a < 128 {
+			continue
+		}
+
+		key := (r>>4)<<8 | (g>>4)<<4 | b>>4
+		bk := buckets[key]
+		if bk == nil {
+			bk = &bucket{}
+			buckets[key] = bk
+		}
+		bk.count++
+		bk.r += r
+		bk.g += g
+		bk.b += b
+
+		if best == nil || bk.count > best.count {
+			best = bk
+		}
+	}
+
+	if best == nil {
+		return "#ffffff" // Fully transparent
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count)
+}
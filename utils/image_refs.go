@@ -0,0 +1,146 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// imageURLKeys are the JSON keys that hold a single uploaded image (or gallery video). Their
+// values count as references even on another host, e.g. a custom domain in front of the bucket.
+// Storage URLs anywhere else, such as images in markdown, are found by the storage URL pattern.
+// Thumbnail keys are not listed because thumbnails are derived from (and deleted together with)
+// their original image.
+var imageURLKeys = map[string]bool{
+	"image_url":               true,
+	"hero_image_url":          true,
+	"header_image_url":        true,
+	"brief_section_image_url": true,
+	"icon_url":                true,
+	"avatar":                  true,
+	"video_url":               true,
+}
+
+// thumbnailURLKeys are the JSON keys that hold thumbnails generated next to an uploaded image
+var thumbnailURLKeys = map[string]bool{
+	"thumbnail_url":            true,
+	"hero_image_thumbnail_url": true,
+}
+
+// ImageRef is an image URL together with the JSON path it was found at,
+// e.g. "destination_detail_sections[2].image_url"
+type ImageRef struct {
+	Path      string `json:"path"`
+	URL       string `json:"url"`
+	Thumbnail bool   `json:"thumbnail"`
+}
+
+// CollectImageURLs returns every image URL referenced by a model, including images
+// nested in JSON columns such as detail sections
+func CollectImageURLs(model interface{}) []string {
+	var urls []string
+	for _, ref := range CollectImageRefs(model) {
+		if !ref.Thumbnail {
+			urls = append(urls, ref.URL)
+		}
+	}
+	return urls
+}
+
+// CollectImageRefs returns every image and thumbnail URL referenced by a model with its JSON path.
+// Besides the image fields, every string is searched for URLs of the storage, so images linked
+// from markdown and rich text are found too.
+func CollectImageRefs(model interface{}) []ImageRef {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	var pattern *regexp.Regexp
+	if Storage != nil {
+		pattern = Storage.urlPattern
+	}
+
+	var refs []ImageRef
+	collectImageRefs(doc, "", pattern, &refs)
+	return refs
+}
+
+func collectImageRefs(value interface{}, path string, pattern *regexp.Regexp, refs *[]ImageRef) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			if url, ok := child.(string); ok && (imageURLKeys[key] || thumbnailURLKeys[key]) {
+				if strings.TrimSpace(url) != "" {
+					*refs = append(*refs, ImageRef{Path: childPath, URL: url, Thumbnail: thumbnailURLKeys[key]})
+				}
+				continue
+			}
+			collectImageRefs(child, childPath, pattern, refs)
+		}
+	case []interface{}:
+		for i, child := range v {
+			collectImageRefs(child, fmt.Sprintf("%s[%d]", path, i), pattern, refs)
+		}
+	case string:
+		if pattern == nil {
+			return
+		}
+		seen := map[string]bool{}
+		for _, match := range pattern.FindAllString(v, -1) {
+			imageURL := strings.TrimRight(match, ".,;:!?")
+			if !seen[imageURL] {
+				seen[imageURL] = true
+				*refs = append(*refs, ImageRef{Path: path, URL: imageURL, Thumbnail: isThumbnailURL(imageURL)})
+			}
+		}
+	}
+}
+
+// storageURLPattern matches URLs under the public URL of a storage backend in free text, up to
+// the first character that can't be part of one: whitespace, quotes, brackets and parentheses
+// (e.g. the end of a markdown image). Returns nil when the public URL has no host, since a bare
+// path would match arbitrary text.
+func storageURLPattern(publicURL string) *regexp.Regexp {
+	parsed, err := url.Parse(publicURL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	return regexp.MustCompile(regexp.QuoteMeta(publicURL) + `[^\s"'<>()\[\]\\]+`)
+}
+
+// isThumbnailURL reports whether a URL is a generated thumbnail, e.g. ".../photo_ab12cd34_thumb.webp"
+func isThumbnailURL(imageURL string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(imageURL, path.Ext(imageURL)), "_thumb")
+}
+
+// ReplacedImageRefs returns the images and thumbnails that before references and after no longer does.
+// Pass stored snapshots as json.RawMessage.
+func ReplacedImageRefs(before, after interface{}) []ImageRef {
+	current := map[string]bool{}
+	for _, ref := range CollectImageRefs(after) {
+		current[ref.URL] = true
+	}
+
+	var replaced []ImageRef
+	seen := map[string]bool{}
+	for _, ref := range CollectImageRefs(before) {
+		if !current[ref.URL] && !seen[ref.URL] {
+			replaced = append(replaced, ref)
+			seen[ref.URL] = true
+		}
+	}
+	return replaced
+}
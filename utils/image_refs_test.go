@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCollectImageRefs(t *testing.T) {
+	previous := Storage
+	Storage = NewStorageService(NewMemoryBackend("https://cdn.example.com/media"))
+	defer func() { Storage = previous }()
+
+	model := map[string]interface{}{
+		"image_url":     "https://cdn.example.com/media/news/a_12345678.webp",
+		"thumbnail_url": "https://cdn.example.com/media/news/a_12345678_thumb.webp",
+		"avatar":        "https://elsewhere.example.com/b.png",
+		"content":       "Intro ![x](https://cdn.example.com/media/news/c_12345678.webp) and https://cdn.example.com/media/news/c_12345678.webp. Not https://elsewhere.example.com/d.png",
+		"sections": []interface{}{
+			map[string]interface{}{
+				"image_url": "",
+				"body":      `<img src="https://cdn.example.com/media/e.png" srcset="https://cdn.example.com/media/e@sm.webp 320w">`,
+			},
+		},
+	}
+
+	want := []ImageRef{
+		{Path: "avatar", URL: "https://elsewhere.example.com/b.png"},
+		{Path: "content", URL: "https://cdn.example.com/media/news/c_12345678.webp"},
+		{Path: "image_url", URL: "https://cdn.example.com/media/news/a_12345678.webp"},
+		{Path: "sections[0].body", URL: "https://cdn.example.com/media/e.png"},
+		{Path: "sections[0].body", URL: "https://cdn.example.com/media/e@sm.webp"},
+		{Path: "thumbnail_url", URL: "https://cdn.example.com/media/news/a_12345678_thumb.webp", Thumbnail: true},
+	}
+	got := CollectImageRefs(model)
+	sort.Slice(got, func(i, j int) bool {
+		if got[i].Path != got[j].Path {
+			return got[i].Path < got[j].Path
+		}
+		return got[i].URL < got[j].URL
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectImageRefs() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCollectImageRefsWithoutStorage(t *testing.T) {
+	previous := Storage
+	Storage = nil
+	defer func() { Storage = previous }()
+
+	got := CollectImageRefs(map[string]interface{}{
+		"image_url": "https://cdn.example.com/media/a.webp",
+		"content":   "![x](https://cdn.example.com/media/b.webp)",
+	})
+	want := []ImageRef{{Path: "image_url", URL: "https://cdn.example.com/media/a.webp"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectImageRefs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReplacedImageRefs(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  map[string]interface{}
+		want   []string
+	}{
+		{
+			name:   "replaced image",
+			before: `{"image_url": "https://cdn/a.webp", "thumbnail_url": "https://cdn/a_thumb.webp"}`,
+			after:  map[string]interface{}{"image_url": "https://cdn/b.webp", "thumbnail_url": "https://cdn/b_thumb.webp"},
+			want:   []string{"https://cdn/a.webp", "https://cdn/a_thumb.webp"},
+		},
+		{
+			name:   "unchanged",
+			before: `{"image_url": "https://cdn/a.webp"}`,
+			after:  map[string]interface{}{"image_url": "https://cdn/a.webp"},
+			want:   nil,
+		},
+		{
+			name:   "moved to another field",
+			before: `{"image_url": "https://cdn/a.webp"}`,
+			after:  map[string]interface{}{"hero_image_url": "https://cdn/a.webp"},
+			want:   nil,
+		},
+		{
+			name:   "removed from a section",
+			before: `{"sections": [{"image_url": "https://cdn/a.webp"}, {"image_url": "https://cdn/b.webp"}]}`,
+			after:  map[string]interface{}{"sections": []interface{}{map[string]interface{}{"image_url": "https://cdn/b.webp"}}},
+			want:   []string{"https://cdn/a.webp"},
+		},
+		{
+			name:   "used twice, reported once",
+			before: `{"image_url": "https://cdn/a.webp", "sections": [{"image_url": "https://cdn/a.webp"}]}`,
+			after:  map[string]interface{}{},
+			want:   []string{"https://cdn/a.webp"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ref := range ReplacedImageRefs(json.RawMessage(tt.before), tt.after) {
+				got = append(got, ref.URL)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReplacedImageRefs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chai2010/webp"
+	"github.com/disintegration/imaging"
+)
+
+// DefaultImageVariants are generated for every uploaded image unless IMAGE_VARIANTS says otherwise
+const DefaultImageVariants = "sm:320,md:640,lg:1024,xl:1600,card:400x400"
+
+// ImageVariantSpec is a named size generated for every uploaded raster image.
+// Variants with a height are cropped to exactly that size (e.g. square card images);
+// the others keep the aspect ratio of the original.
+type ImageVariantSpec struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Crop reports whether the variant is cropped to a fixed size
+func (s ImageVariantSpec) Crop() bool {
+	return s.Height > 0
+}
+
+// ImageVariant is a resized copy of an uploaded image. Every variant is WebP;
+// AVIFURL is set when AVIF encoding is enabled.
+type ImageVariant struct {
+	Key     string `json:"key"`
+	URL     string `json:"url"`
+	AVIFKey string `json:"avif_key,omitempty"`
+	AVIFURL string `json:"avif_url,omitempty"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Crop    bool   `json:"crop"`
+	Size    int64  `json:"size"`
+}
+
+var variantNamePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// ParseImageVariants parses a variant list such as "sm:320,md:640,card:400x400".
+// A width alone keeps the aspect ratio, WIDTHxHEIGHT crops to that size. "none" disables variants.
+func ParseImageVariants(spec string) ([]ImageVariantSpec, error) {
+	var variants []ImageVariantSpec
+	seen := map[string]bool{}
+
+	if strings.EqualFold(strings.TrimSpace(spec), "none") {
+		return variants, nil
+	}
+
+	for _, item := range SplitList(spec) {
+		name, size, ok := strings.Cut(item, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || !variantNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid image variant %q, expected name:width or name:widthxheight", item)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate image variant %q", name)
+		}
+		seen[name] = true
+
+		variant := ImageVariantSpec{Name: name}
+		widthValue, heightValue, crop := strings.Cut(strings.ToLower(strings.TrimSpace(size)), "x")
+		width, err := strconv.Atoi(widthValue)
+		if err != nil || width <= 0 {
+			return nil, fmt.Errorf("invalid width in image variant %q", item)
+		}
+		variant.Width = width
+		if crop {
+			height, err := strconv.Atoi(heightValue)
+			if err != nil || height <= 0 {
+				return nil, fmt.Errorf("invalid height in image variant %q", item)
+			}
+			variant.Height = height
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// variantKey names a variant after its original: "news/photo_ab12cd34.webp" -> "news/photo_ab12cd34@md.webp"
+func variantKey(key, name, ext string) string {
+	return fmt.Sprintf("%s@%s%s", strings.TrimSuffix(key, filepath.Ext(key)), name, ext)
+}
+
+// variantSize returns the size of a variant of a width x height image. Images are never
+// upscaled: ok is false when a resized variant would not be smaller than the original.
+func variantSize(spec ImageVariantSpec, width, height int) (w, h int, ok bool) {
+	if !spec.Crop() {
+		if spec.Width >= width {
+			return 0, 0, false
+		}
+		return spec.Width, int(math.Round(float64(height) * float64(spec.Width) / float64(width))), true
+	}
+
+	// Scale the crop down when the original is smaller than it
+	scale := math.Min(1, math.Min(float64(width)/float64(spec.Width), float64(height)/float64(spec.Height)))
+	w = int(math.Round(float64(spec.Width) * scale))
+	h = int(math.Round(float64(spec.Height) * scale))
+	return w, h, w > 0 && h > 0
+}
+
+// uploadVariants resizes img to every configured variant and uploads them next to the original
+func (s *StorageService) uploadVariants(img image.Image, key string) (map[string]ImageVariant, error) {
+	return s.uploadVariantSpecs(img, key, s.variants, CenterFocus)
+}
+
+// uploadVariantSpecs uploads the given variants of img; cropped variants keep the focus in view
+func (s *StorageService) uploadVariantSpecs(img image.Image, key string, specs []ImageVariantSpec, focus ImageFocus) (map[string]ImageVariant, error) {
+	bounds := img.Bounds()
+	variants := map[string]ImageVariant{}
+
+	// Cropped variants are cut from the crop rectangle, so it limits their size
+	cropWidth, cropHeight := bounds.Dx(), bounds.Dy()
+	if focus.Crop != nil {
+		cropWidth = int(math.Round(float64(cropWidth) * focus.Crop.Width / 100))
+		cropHeight = int(math.Round(float64(cropHeight) * focus.Crop.Height / 100))
+	}
+
+	for _, spec := range specs {
+		var width, height int
+		var ok bool
+		if spec.Crop() {
+			width, height, ok = variantSize(spec, cropWidth, cropHeight)
+		} else {
+			width, height, ok = variantSize(spec, bounds.Dx(), bounds.Dy())
+		}
+		if !ok {
+			continue
+		}
+
+		var resized image.Image
+		if spec.Crop() {
+			resized = fillFocused(img, width, height, focus)
+		} else {
+			resized = imaging.Resize(img, width, height, imaging.Lanczos)
+		}
+
+		var buf bytes.Buffer
+		if err := webp.Encode(&buf, resized, &webp.Options{Quality: 80}); err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %v", spec.Name, err)
+		}
+
+		variant := ImageVariant{
+			Key:    variantKey(key, spec.Name, ".webp"),
+			Width:  width,
+			Height: height,
+			Crop:   spec.Crop(),
+			Size:   int64(buf.Len()),
+		}
+		if err := s.putObject(variant.Key, buf.Bytes(), "image/webp"); err != nil {
+			return nil, fmt.Errorf("failed to upload %s variant: %v", spec.Name, err)
+		}
+		variant.URL = s.backend.PublicURL(variant.Key)
+
+		if s.avif {
+			avif, err := encodeAVIF(resized)
+			if err != nil {
+				// WebP is always there, so a missing AVIF copy is not worth failing the upload over
+				fmt.Printf("Warning: Failed to encode %s variant to AVIF: %v\n", spec.Name, err)
+			} else {
+				avifKey := variantKey(key, spec.Name, ".avif")
+				if err := s.putObject(avifKey, avif, "image/avif"); err != nil {
+					return nil, fmt.Errorf("failed to upload %s AVIF variant: %v", spec.Name, err)
+				}
+				variant.AVIFKey = avifKey
+				variant.AVIFURL = s.backend.PublicURL(avifKey)
+			}
+		}
+
+		variants[spec.Name] = variant
+	}
+
+	return variants, nil
+}
+
+// deleteVariants deletes every configured variant of an image; missing variants are ignored
+func (s *StorageService) deleteVariants(key string) {
+	for _, spec := range s.variants {
+		_ = s.DeleteObject(variantKey(key, spec.Name, ".webp"))
+		if s.avif {
+			_ = s.DeleteObject(variantKey(key, spec.Name, ".avif"))
+		}
+	}
+}
+
+// BuildSrcSet builds a srcset attribute from the variants that keep the aspect ratio,
+// with the original image as the largest candidate
+func BuildSrcSet(imageURL string, width int, variants map[string]ImageVariant) string {
+	var candidates []ImageVariant
+	for _, variant := range variants {
+		if !variant.Crop {
+			candidates = append(candidates, variant)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Width < candidates[j].Width })
+
+	parts := make([]string, 0, len(candidates)+1)
+	for _, variant := range candidates {
+		parts = append(parts, fmt.Sprintf("%s %dw", variant.URL, variant.Width))
+	}
+	if width > 0 {
+		parts = append(parts, fmt.Sprintf("%s %dw", imageURL, width))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// encodeAVIF encodes an image with avifenc (libavif), which has to be on the PATH
+func encodeAVIF(img image.Image) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "avif-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input.png")
+	output := filepath.Join(dir, "output.avif")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(input, buf.Bytes(), 0o600); err != nil {
+		return nil, err
+	}
+
+	if _, err := runTool("avifenc", "--speed", "8", "--min", "20", "--max", "40", input, output); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(output)
+}
+
+// AVIFAvailable reports whether avifenc can be found on the PATH
+func AVIFAvailable() bool {
+	_, err := exec.LookPath("avifenc")
+	return err == nil
+}
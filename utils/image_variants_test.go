@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImageVariants(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []ImageVariantSpec
+		wantErr bool
+	}{
+		{spec: "sm:320,md:640", want: []ImageVariantSpec{{Name: "sm", Width: 320}, {Name: "md", Width: 640}}},
+		{spec: " Card : 400X300 ", want: []ImageVariantSpec{{Name: "card", Width: 400, Height: 300}}},
+		{spec: "none", want: nil},
+		{spec: "NONE", want: nil},
+		{spec: "sm", wantErr: true},
+		{spec: "sm:abc", wantErr: true},
+		{spec: "sm:0", wantErr: true},
+		{spec: "sm:-5", wantErr: true},
+		{spec: "card:400x", wantErr: true},
+		{spec: "card:400x0", wantErr: true},
+		{spec: "sm:320,sm:640", wantErr: true},
+		{spec: "s m:320", wantErr: true},
+		{spec: "../x:320", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseImageVariants(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseImageVariants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseImageVariants() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultImageVariantsParse(t *testing.T) {
+	if _, err := ParseImageVariants(DefaultImageVariants); err != nil {
+		t.Fatalf("DefaultImageVariants: %v", err)
+	}
+}
+
+func TestVariantSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          ImageVariantSpec
+		width, height int
+		wantW, wantH  int
+		wantOK        bool
+	}{
+		{"scales down keeping the ratio", ImageVariantSpec{Width: 640}, 1600, 1200, 640, 480, true},
+		{"rounds the height", ImageVariantSpec{Width: 320}, 1000, 333, 320, 107, true},
+		{"never upscales", ImageVariantSpec{Width: 640}, 400, 300, 0, 0, false},
+		{"skips the same width", ImageVariantSpec{Width: 640}, 640, 480, 0, 0, false},
+		{"crops to the size", ImageVariantSpec{Width: 400, Height: 400}, 1600, 1200, 400, 400, true},
+		{"scales a crop down to fit a small image", ImageVariantSpec{Width: 400, Height: 400}, 200, 300, 200, 200, true},
+		{"keeps the crop ratio when scaling down", ImageVariantSpec{Width: 800, Height: 400}, 400, 1000, 400, 200, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, ok := variantSize(tt.spec, tt.width, tt.height)
+			if w != tt.wantW || h != tt.wantH || ok != tt.wantOK {
+				t.Errorf("variantSize() = %d, %d, %v, want %d, %d, %v", w, h, ok, tt.wantW, tt.wantH, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestUploadVariants(t *testing.T) {
+	service := NewStorageService(NewMemoryBackend("https://cdn.example.com/media"))
+
+	result, err := service.UploadImageContent(testPNG(t, 800, 600), "photo.png", "gallery")
+	if err != nil {
+		t.Fatalf("UploadImageContent() error = %v", err)
+	}
+	want := map[string][2]int{"sm": {320, 240}, "md": {640, 480}, "card": {400, 400}}
+	if len(result.Variants) != len(want) {
+		t.Errorf("Variants = %v, want %v and none wider than the image", result.Variants, want)
+	}
+	for name, size := range want {
+		variant, ok := result.Variants[name]
+		if !ok || variant.Width != size[0] || variant.Height != size[1] || variant.Crop != (name == "card") {
+			t.Errorf("variant %s = %+v, want %dx%d", name, variant, size[0], size[1])
+		}
+	}
+	if !strings.Contains(result.SrcSet, result.Variants["sm"].URL+" 320w") || strings.Contains(result.SrcSet, result.Variants["card"].URL) {
+		t.Errorf("SrcSet = %q, want the variants that keep the aspect ratio", result.SrcSet)
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Media types of uploads and gallery items
+const (
+	MediaTypeImage    = "image"
+	MediaTypeVideo    = "video"
+	MediaTypePanorama = "panorama" // Equirectangular 360° image
+)
+
+// panoramaMaxDimension is the longest side panoramas are resized to. An equirectangular image
+// spans the full 360°, so the viewer only ever shows a fraction of its width.
+const panoramaMaxDimension = 8192
+
+// toolTimeout bounds every run of an external tool (ffmpeg, ffprobe, avifenc), so a file that
+// makes one hang can't hold on to an upload request and its temporary files
+const toolTimeout = 2 * time.Minute
+
+// runTool runs an external tool with toolTimeout and returns what it wrote to stdout. Errors
+// include what it wrote to stderr.
+func runTool(name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s: timed out after %s", name, toolTimeout)
+		}
+		return nil, fmt.Errorf("%s: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// IsVideoFile reports whether a file is an MP4 or WebM video, by its extension
+func IsVideoFile(filename string) bool {
+	return strings.HasPrefix(ContentTypeFromFilename(filename), "video/")
+}
+
+// IsEquirectangular reports whether an image carries the Google Photo Sphere (GPano) XMP
+// metadata that phones and 360° cameras write into equirectangular panoramas
+func IsEquirectangular(content []byte) bool {
+	i := bytes.Index(content, []byte("GPano:ProjectionType"))
+	if i < 0 {
+		return false
+	}
+	// The value follows as an attribute or an element: ProjectionType="equirectangular"
+	window := content[i:min(len(content), i+64)]
+	return bytes.Contains(window, []byte("equirectangular"))
+}
+
+// ContentHash returns the SHA-256 of a file as hex. Uploads are deduplicated by the hash of
+// the file as it was uploaded, before any conversion.
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadMultipartFile reads an uploaded file into memory
+func ReadMultipartFile(file *multipart.FileHeader) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer src.Close()
+
+	fileContent, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return fileContent, nil
+}
+
+// UploadMedia uploads an image, panorama or video file. See UploadMediaContent.
+func (s *StorageService) UploadMedia(file *multipart.FileHeader, folder, mediaType string) (*UploadResponse, error) {
+	fileContent, err := ReadMultipartFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadMediaContent(fileContent, file.Filename, folder, mediaType)
+}
+
+// UploadMediaContent uploads a file by its media type. The type of the file is detected from
+// its content and must be allowed and match its extension; images are treated as panoramas
+// when mediaType says so or their metadata does.
+func (s *StorageService) UploadMediaContent(fileContent []byte, originalFilename, folder, mediaType string) (*UploadResponse, error) {
+	contentType, err := s.checkUpload(fileContent, originalFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	var response *UploadResponse
+	switch {
+	case strings.HasPrefix(contentType, "video/"):
+		response, err = s.UploadVideoContent(fileContent, originalFilename, folder)
+	case mediaType == MediaTypeVideo:
+		return nil, fmt.Errorf("unsupported video format %q, use MP4 or WebM", filepath.Ext(originalFilename))
+	default:
+		panorama := mediaType == MediaTypePanorama || IsEquirectangular(fileContent)
+		response, err = s.uploadImageContent(fileContent, originalFilename, folder, panorama)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response.ContentHash = ContentHash(fileContent)
+	return response, nil
+}
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// VideoInfo is the duration and resolution of a video, read with ffprobe
+type VideoInfo struct {
+	DurationSeconds float64
+	Width           int
+	Height          int
+}
+
+// FFmpegAvailable reports whether ffmpeg and ffprobe can be found on the PATH. Without
+// them videos are stored as they are, including metadata such as the GPS location phones
+// record, and without poster frame, duration or resolution.
+func FFmpegAvailable() bool {
+	for _, name := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// UploadVideoContent uploads an MP4 or WebM video. When ffmpeg is installed, the video is
+// stored without its metadata (location, device, ...), its duration and resolution are read and
+// a poster frame is uploaded next to it as an image, with the usual thumbnail, variants and
+// placeholder. Without ffmpeg the video is stored as uploaded.
+func (s *StorageService) UploadVideoContent(fileContent []byte, originalFilename, folder string) (*UploadResponse, error) {
+	baseFilename, ext := keyFilename(originalFilename)
+	ext = strings.ToLower(ext)
+	uniqueID := uuid.New().String()[:8]
+
+	contentType := ContentTypeFromFilename(originalFilename)
+	if !strings.HasPrefix(contentType, "video/") {
+		return nil, fmt.Errorf("unsupported video format %q, use MP4 or WebM", ext)
+	}
+
+	key := fmt.Sprintf("%s/%s_%s%s", folder, baseFilename, uniqueID, ext)
+	ffmpeg := FFmpegAvailable()
+	if ffmpeg {
+		stripped, err := stripVideoMetadata(fileContent, ext)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the video could not be read: %v", ErrFileTypeNotAllowed, err)
+		}
+		fileContent = stripped
+	} else {
+		fmt.Printf("Warning: ffmpeg was not found, video %s is stored with its metadata and has no poster frame or duration\n", key)
+	}
+
+	if err := s.putObject(key, fileContent, contentType); err != nil {
+		return nil, fmt.Errorf("failed to upload video: %v", err)
+	}
+
+	response := &UploadResponse{
+		Success:     true,
+		FileURL:     s.backend.PublicURL(key),
+		FileSize:    int64(len(fileContent)),
+		Key:         key,
+		ContentType: contentType,
+		MediaType:   MediaTypeVideo,
+	}
+
+	if !ffmpeg {
+		return response, nil
+	}
+
+	info, poster, err := inspectVideo(fileContent, ext)
+	if err != nil {
+		// The video itself is stored and playable
+		fmt.Printf("Warning: Failed to inspect video %s: %v\n", key, err)
+		return response, nil
+	}
+
+	response.DurationSeconds = info.DurationSeconds
+	response.Dimensions = &ImageDimensions{Width: info.Width, Height: info.Height}
+
+	posterResponse, err := s.uploadImageContent(poster, baseFilename+"_poster.png", folder, false)
+	if err != nil {
+		fmt.Printf("Warning: Failed to upload poster frame of video %s: %v\n", key, err)
+		return response, nil
+	}
+	response.Poster = posterResponse
+	response.ThumbnailURL = posterResponse.ThumbnailURL
+	response.ThumbnailKey = posterResponse.ThumbnailKey
+
+	return response, nil
+}
+
+// stripVideoMetadata remuxes a video without its metadata, such as the GPS location and
+// device phones record, and without data streams, which can carry the location too. The video
+// and audio streams are copied as they are.
+func stripVideoMetadata(content []byte, ext string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "video-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input"+ext)
+	output := filepath.Join(dir, "output"+ext)
+	if err := os.WriteFile(input, content, 0o600); err != nil {
+		return nil, err
+	}
+
+	if _, err := runTool("ffmpeg", "-v", "error", "-y", "-i", input,
+		"-map", "0:v", "-map", "0:a?", "-map_metadata", "-1", "-map_chapters", "-1",
+		"-c", "copy", output); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(output)
+}
+
+// inspectVideo reads the duration and resolution of a video with ffprobe and grabs a poster
+// frame as PNG with ffmpeg
+func inspectVideo(content []byte, ext string) (*VideoInfo, []byte, error) {
+	dir, err := os.MkdirTemp("", "video-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input"+ext)
+	output := filepath.Join(dir, "poster.png")
+	if err := os.WriteFile(input, content, 0o600); err != nil {
+		return nil, nil, err
+	}
+
+	info, err := probeVideo(input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Skip the first second, which is often a black fade-in, unless the video is shorter
+	offset := 1.0
+	if info.DurationSeconds < 2 {
+		offset = info.DurationSeconds / 2
+	}
+	if _, err := runTool("ffmpeg", "-v", "error", "-y",
+		"-ss", strconv.FormatFloat(offset, 'f', 3, 64), "-i", input,
+		"-frames:v", "1", "-f", "image2", "-c:v", "png", output); err != nil {
+		return nil, nil, err
+	}
+
+	poster, err := os.ReadFile(output)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info, poster, nil
+}
+
+// probeVideo reads the duration of a video file and the resolution of its first video stream
+func probeVideo(path string) (*VideoInfo, error) {
+	out, err := runTool("ffprobe", "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=width,height:stream_side_data=rotation:format=duration",
+		"-of", "json", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var probe struct {
+		Streams []struct {
+			Width    int `json:"width"`
+			Height   int `json:"height"`
+			SideData []struct {
+				Rotation int `json:"rotation"`
+			} `json:"side_data_list"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("ffprobe: %v", err)
+	}
+	if len(probe.Streams) == 0 {
+		return nil, fmt.Errorf("no video stream found")
+	}
+
+	stream := probe.Streams[0]
+	info := &VideoInfo{Width: stream.Width, Height: stream.Height}
+	// Phone videos are stored sideways with a rotation, like photos with an EXIF orientation
+	for _, side := range stream.SideData {
+		if side.Rotation == 90 || side.Rotation == -90 || side.Rotation == 270 || side.Rotation == -270 {
+			info.Width, info.Height = info.Height, info.Width
+		}
+	}
+	info.DurationSeconds, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	return info, nil
+}
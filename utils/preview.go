@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"errors"
+	"time"
+	"yaro-wora-be/config"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// PreviewClaim identifies the single content item a preview token grants access to
+type PreviewClaim struct {
+	ContentType string `json:"content_type"`
+	ContentID   uint   `json:"content_id"`
+	jwt.RegisteredClaims
+}
+
+// previewSigningKey derives a separate key so preview tokens can never be used as admin tokens
+func previewSigningKey() []byte {
+	return []byte(config.AppConfig.JWTSecret + ":preview")
+}
+
+// GeneratePreviewToken generates a signed, expiring preview token for a content item
+func GeneratePreviewToken(contentType string, contentID uint, issuedBy string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := PreviewClaim{
+		ContentType: contentType,
+		ContentID:   contentID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   issuedBy,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(previewSigningKey())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ValidatePreviewToken validates a preview token and returns its claims
+func ValidatePreviewToken(tokenString string) (*PreviewClaim, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &PreviewClaim{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return previewSigningKey(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*PreviewClaim)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid preview token")
+	}
+
+	return claims, nil
+}
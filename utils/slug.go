@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Slugify turns a title into a lowercase, dash separated, URL-safe slug.
+// Accents are stripped ("Kampung Adat Praijing" -> "kampung-adat-praijing").
+func Slugify(title string) string {
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	plain, _, err := transform.String(stripAccents, title)
+	if err != nil {
+		plain = title
+	}
+
+	var b strings.Builder
+	pendingDash := false
+	for _, r := range strings.ToLower(plain) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			pendingDash = false
+			continue
+		}
+		pendingDash = true
+	}
+
+	slug := b.String()
+	if len(slug) > 200 {
+		slug = strings.TrimRight(slug[:200], "-")
+	}
+	return slug
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SpreadsheetRow is a data row keyed by normalized header name
+type SpreadsheetRow struct {
+	Number int               // Row number as shown in the spreadsheet (the header is row 1)
+	Values map[string]string // Normalized header -> trimmed cell value
+}
+
+// Get returns the first non-empty value among the given columns
+func (r SpreadsheetRow) Get(columns ...string) string {
+	for _, column := range columns {
+		if value := r.Values[column]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// ReadSpreadsheet reads a CSV or XLSX file (first sheet) whose first row holds the column headers.
+// Headers are normalized to snake_case so "Question ID" and "question_id" are the same column.
+// Empty rows are skipped.
+func ReadSpreadsheet(filename string, r io.Reader) ([]SpreadsheetRow, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		if records, err = reader.ReadAll(); err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+	case ".xlsx":
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid XLSX: %v", err)
+		}
+		defer file.Close()
+		if records, err = file.GetRows(file.GetSheetName(0)); err != nil {
+			return nil, fmt.Errorf("invalid XLSX: %v", err)
+		}
+	default:
+		return nil, errors.New("only .csv and .xlsx files are supported")
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("the file is empty")
+	}
+
+	headers := make([]string, len(records[0]))
+	for i, header := range records[0] {
+		headers[i] = normalizeHeader(header)
+	}
+
+	var rows []SpreadsheetRow
+	for i, record := range records[1:] {
+		row := SpreadsheetRow{Number: i + 2, Values: map[string]string{}}
+		empty := true
+		for j, cell := range record {
+			if j >= len(headers) || headers[j] == "" {
+				continue
+			}
+			if cell = strings.TrimSpace(cell); cell != "" {
+				empty = false
+			}
+			row.Values[headers[j]] = cell
+		}
+		if !empty {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// SplitList splits a cell holding a list ("a, b; c") into its trimmed, non-empty items
+func SplitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// normalizeHeader turns "Question (ID)" or "Question-ID" into "question_id"
+func normalizeHeader(header string) string {
+	header = strings.TrimPrefix(header, "\ufeff") // Excel writes a BOM in front of CSV exports
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(header)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+			underscore = false
+		} else {
+			underscore = true
+		}
+	}
+	return b.String()
+}
@@ -9,158 +9,245 @@ import (
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
-	"io"
 	"mime/multipart"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	appConfig "yaro-wora-be/config"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/chai2010/webp"
 	"github.com/disintegration/imaging"
 	"github.com/google/uuid"
 	_ "golang.org/x/image/webp" // WebP decoder
 )
 
+// StorageService uploads, resizes and deletes images on a storage backend
 type StorageService struct {
-	client     *s3.Client
-	bucketName string
-	endpoint   string // S3 API endpoint
-	publicURL  string // Public URL for file access
+	backend       StorageBackend
+	variants      []ImageVariantSpec // Responsive sizes generated for every raster image
+	avif          bool               // Also encode variants to AVIF
+	thumbnailSize int                // Thumbnails fit in a square of this size
+	usage         *usageTracker      // Running usage totals for analytics and the storage limit
+
+	uploadTypes map[string]bool // Content types accepted for upload
+	maxPixels   int             // Largest image (width × height) accepted for upload
+
+	urlPattern *regexp.Regexp // Finds URLs of this storage in free text, nil without a public host
 }
 
 var Storage *StorageService
 
-// InitStorage initializes the Cloudflare R2 storage service
+// NewStorageService creates a storage service on top of a backend with the default image variants
+func NewStorageService(backend StorageBackend) *StorageService {
+	variants, _ := ParseImageVariants(DefaultImageVariants)
+	uploadTypes, _ := ParseUploadTypes(DefaultUploadTypes)
+	return &StorageService{
+		backend:       backend,
+		variants:      variants,
+		thumbnailSize: 320,
+		usage:         &usageTracker{},
+		uploadTypes:   uploadTypes,
+		maxPixels:     DefaultImageMaxPixels,
+		urlPattern:    storageURLPattern(backend.PublicURL("")),
+	}
+}
+
+// InitStorage initializes the storage service with the backend selected by STORAGE_BACKEND.
+// Storage stays nil when the backend can't be set up.
 func InitStorage() error {
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(appConfig.AppConfig.R2Region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+	var backend StorageBackend
+	var err error
+
+	switch appConfig.AppConfig.StorageBackend {
+	case StorageBackendR2:
+		backend, err = NewR2Backend(
+			appConfig.AppConfig.R2Endpoint,
+			appConfig.AppConfig.R2Region,
 			appConfig.AppConfig.R2AccessKey,
 			appConfig.AppConfig.R2SecretKey,
-			"",
-		)),
-	)
+			appConfig.AppConfig.R2BucketName,
+			appConfig.AppConfig.R2PublicURL,
+		)
+	case StorageBackendLocal:
+		backend, err = NewLocalBackend(appConfig.AppConfig.LocalStorageDir, appConfig.AppConfig.LocalStoragePublicURL)
+	case StorageBackendMemory:
+		backend = NewMemoryBackend(appConfig.AppConfig.LocalStoragePublicURL)
+	default:
+		err = fmt.Errorf("unknown STORAGE_BACKEND %q", appConfig.AppConfig.StorageBackend)
+	}
 	if err != nil {
-		return fmt.Errorf("unable to load SDK config: %v", err)
+		return err
 	}
 
-	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.BaseEndpoint = aws.String(appConfig.AppConfig.R2Endpoint)
-	})
-
-	Storage = &StorageService{
-		client:     client,
-		bucketName: appConfig.AppConfig.R2BucketName,
-		endpoint:   appConfig.AppConfig.R2Endpoint,
-		publicURL:  appConfig.AppConfig.R2PublicURL,
+	service := NewStorageService(backend)
+	if service.variants, err = ParseImageVariants(appConfig.AppConfig.ImageVariants); err != nil {
+		return fmt.Errorf("IMAGE_VARIANTS: %v", err)
+	}
+	if service.uploadTypes, err = ParseUploadTypes(appConfig.AppConfig.UploadAllowedTypes); err != nil {
+		return fmt.Errorf("UPLOAD_ALLOWED_TYPES: %v", err)
+	}
+	if appConfig.AppConfig.ImageMaxPixels > 0 {
+		service.maxPixels = appConfig.AppConfig.ImageMaxPixels
+	}
+	if appConfig.AppConfig.ImageThumbnailSize > 0 {
+		service.thumbnailSize = appConfig.AppConfig.ImageThumbnailSize
+	}
+	if appConfig.AppConfig.ImageAVIF {
+		if AVIFAvailable() {
+			service.avif = true
+		} else {
+			fmt.Printf("Warning: IMAGE_AVIF is enabled but avifenc was not found, only WebP variants will be generated\n")
+		}
 	}
 
+	Storage = service
 	return nil
 }
 
-// UploadImage uploads an image file to R2 and returns the URL
+// PresignUpload returns a presigned URL for uploading a file straight to storage, or
+// ErrPresignNotSupported when the backend has no presigned URLs
+func (s *StorageService) PresignUpload(key, contentType string, size int64, expires time.Duration) (*PresignedUpload, error) {
+	presigner, ok := s.backend.(Presigner)
+	if !ok {
+		return nil, ErrPresignNotSupported
+	}
+	return presigner.PresignPut(context.TODO(), key, contentType, size, expires)
+}
+
+// GetObject returns the content of a stored object
+func (s *StorageService) GetObject(key string) ([]byte, error) {
+	return s.backend.Get(context.TODO(), key)
+}
+
+// UploadImage uploads an image file to R2 as it is and returns the URL
 func (s *StorageService) UploadImage(file *multipart.FileHeader, folder string) (string, error) {
 	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("%s_%s%s",
-		strings.TrimSuffix(file.Filename, ext),
-		uuid.New().String()[:8],
-		ext,
-	)
+	baseFilename, ext := keyFilename(file.Filename)
+	filename := fmt.Sprintf("%s_%s%s", baseFilename, uuid.New().String()[:8], ext)
 
 	// Create full path
 	key := fmt.Sprintf("%s/%s", folder, filename)
 
-	// Open file
-	src, err := file.Open()
+	// Read file content
+	fileContent, err := ReadMultipartFile(file)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %v", err)
+		return "", err
 	}
-	defer src.Close()
 
-	// Read file content
-	fileContent, err := io.ReadAll(src)
+	// Determine content type from the content itself
+	contentType, err := s.checkUpload(fileContent, file.Filename)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
+		return "", err
+	}
+	if contentType == "image/svg+xml" {
+		if fileContent, err = SanitizeSVG(fileContent); err != nil {
+			return "", err
+		}
 	}
 
-	// Determine content type
-	contentType := getContentType(file.Filename)
-
-	// Upload to R2
-	_, err = s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucketName),
-		Key:         aws.String(key),
-		Body:        strings.NewReader(string(fileContent)),
-		ContentType: aws.String(contentType),
-		ACL:         "public-read", // Make publicly accessible
-	})
-	if err != nil {
+	// Upload to storage
+	if err := s.putObject(key, fileContent, contentType); err != nil {
 		return "", fmt.Errorf("failed to upload file: %v", err)
 	}
 
 	// Return public URL
-	imageURL := fmt.Sprintf("%s/%s", s.publicURL, key)
+	imageURL := s.backend.PublicURL(key)
 	return imageURL, nil
 }
 
-// UploadImageWithThumbnail uploads an image and its thumbnail to R2, returns URLs and dimensions
-// JPEG/JPG/PNG images are converted to WebP format before upload
+// UploadImageWithThumbnail uploads an image, its thumbnail and its responsive variants,
+// returns URLs and dimensions. JPEG/JPG/PNG images are converted to WebP format before upload
 func (s *StorageService) UploadImageWithThumbnail(file *multipart.FileHeader, folder string) (*UploadResponse, error) {
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	baseFilename := strings.TrimSuffix(file.Filename, ext)
-	uniqueID := uuid.New().String()[:8]
-
-	// Open and read file
-	src, err := file.Open()
+	fileContent, err := ReadMultipartFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, err
 	}
-	defer src.Close()
+	return s.UploadImageContent(fileContent, file.Filename, folder)
+}
 
-	fileContent, err := io.ReadAll(src)
+// UploadImageContent is UploadImageWithThumbnail for an image that is already in memory,
+// e.g. one uploaded straight to storage with a presigned URL. Photos tagged as 360° panoramas
+// are kept at panorama resolution.
+func (s *StorageService) UploadImageContent(fileContent []byte, originalFilename, folder string) (*UploadResponse, error) {
+	contentType, err := s.checkUpload(fileContent, originalFilename)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %v", err)
+		return nil, err
 	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%w: %s is not an image", ErrFileTypeNotAllowed, contentType)
+	}
+	return s.uploadImageContent(fileContent, originalFilename, folder, IsEquirectangular(fileContent))
+}
+
+func (s *StorageService) uploadImageContent(fileContent []byte, originalFilename, folder string, panorama bool) (*UploadResponse, error) {
+	// Generate unique filename
+	baseFilename, ext := keyFilename(originalFilename)
+	uniqueID := uuid.New().String()[:8]
 
+	var err error
 	// Check if file is SVG - skip conversion and thumbnail creation for SVG files
-	isSVG := strings.ToLower(ext) == ".svg"
+	isSVG := DetectContentType(fileContent) == "image/svg+xml"
 
 	var width, height int
-	var thumbnailURL string
+	var thumbnailURL, thumbnailKey string
+	var img image.Image
+	var exifData *ImageExif
 	var finalFileContent []byte
 	var finalExt string
 	var finalContentType string
 
 	if isSVG {
-		// For SVG, keep original content and extension
-		finalFileContent = fileContent
-		finalExt = ext
+		// SVGs are served from our domain and can carry scripts; keep only the drawing
+		finalFileContent, err = SanitizeSVG(fileContent)
+		if err != nil {
+			return nil, err
+		}
+		finalExt = ".svg"
 		finalContentType = "image/svg+xml"
 	} else {
+		// Check the dimensions in the header before decoding allocates the pixels
+		header, _, headerErr := image.DecodeConfig(bytes.NewReader(fileContent))
+		if headerErr != nil {
+			return nil, fmt.Errorf("failed to decode image: %v", headerErr)
+		}
+		if err := s.checkImagePixels(header.Width, header.Height); err != nil {
+			return nil, err
+		}
+
 		// Decode image to get dimensions for non-SVG files
-		img, format, err := image.Decode(bytes.NewReader(fileContent))
+		var format string
+		img, format, err = image.Decode(bytes.NewReader(fileContent))
 		if err != nil {
 			return nil, fmt.Errorf("failed to decode image: %v", err)
 		}
 
+		// Phones store portrait shots sideways with an EXIF orientation tag; turn the pixels
+		// instead, since published files carry no metadata
+		exifData = readImageExif(fileContent, format)
+		transformed := false
+		if exifData != nil && exifData.orientation > 1 {
+			img = applyOrientation(img, exifData.orientation)
+			transformed = true
+		}
+
 		bounds := img.Bounds()
 		width = bounds.Dx()
 		height = bounds.Dy()
 
-		// Resize large images before conversion for better performance
-		const maxDimension = 2048
+		// Resize large images before conversion for better performance. Panoramas wrap
+		// around the viewer and need the extra resolution.
+		maxDimension := 2048
+		if panorama {
+			maxDimension = panoramaMaxDimension
+		}
 		if width > maxDimension || height > maxDimension {
 			img = imaging.Fit(img, maxDimension, maxDimension, imaging.Lanczos)
 			bounds = img.Bounds()
 			width = bounds.Dx()
 			height = bounds.Dy()
+			transformed = true
 		}
 
 		// Determine if we need to convert to WebP
@@ -197,8 +284,8 @@ func (s *StorageService) UploadImageWithThumbnail(file *multipart.FileHeader, fo
 			finalContentType = "image/webp"
 		} else {
 			// Keep original format (WebP, GIF, etc.)
-			// But re-encode it if we resized it
-			if width != bounds.Dx() || height != bounds.Dy() {
+			// But re-encode it if we resized or rotated it
+			if transformed {
 				var buf bytes.Buffer
 				switch format {
 				case "webp":
@@ -215,26 +302,23 @@ func (s *StorageService) UploadImageWithThumbnail(file *multipart.FileHeader, fo
 					return nil, fmt.Errorf("failed to re-encode resized image: %v", err)
 				}
 				finalFileContent = buf.Bytes()
+			} else if format == "webp" {
+				// Re-encoded files have no metadata; drop it from WebPs uploaded as they are
+				finalFileContent = stripWebPMetadata(fileContent)
 			} else {
 				finalFileContent = fileContent
 			}
 			finalExt = ext
 			if finalContentType == "" {
-				finalContentType = getContentType(file.Filename)
+				finalContentType = "image/" + format
 			}
 		}
 
-		// Generate thumbnail (10% of original size)
-		thumbnailWidth := width / 10
-		thumbnailHeight := height / 10
-		if thumbnailWidth < 1 {
-			thumbnailWidth = 1
+		// Generate thumbnail, never larger than the image itself
+		thumbnail := img
+		if width > s.thumbnailSize || height > s.thumbnailSize {
+			thumbnail = imaging.Fit(img, s.thumbnailSize, s.thumbnailSize, imaging.Lanczos)
 		}
-		if thumbnailHeight < 1 {
-			thumbnailHeight = 1
-		}
-
-		thumbnail := imaging.Resize(img, thumbnailWidth, thumbnailHeight, imaging.Lanczos)
 
 		// Encode thumbnail to WebP for JPEG/PNG, or keep original format for others
 		var thumbnailBuf bytes.Buffer
@@ -268,20 +352,13 @@ func (s *StorageService) UploadImageWithThumbnail(file *multipart.FileHeader, fo
 
 		// Upload thumbnail
 		thumbnailFilename := fmt.Sprintf("%s_%s_thumb%s", baseFilename, uniqueID, thumbnailExt)
-		thumbnailKey := fmt.Sprintf("%s/%s", folder, thumbnailFilename)
-		_, err = s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-			Bucket:      aws.String(s.bucketName),
-			Key:         aws.String(thumbnailKey),
-			Body:        bytes.NewReader(thumbnailBuf.Bytes()),
-			ContentType: aws.String(thumbnailContentType),
-			ACL:         "public-read",
-		})
-		if err != nil {
+		thumbnailKey = fmt.Sprintf("%s/%s", folder, thumbnailFilename)
+		if err := s.putObject(thumbnailKey, thumbnailBuf.Bytes(), thumbnailContentType); err != nil {
 			return nil, fmt.Errorf("failed to upload thumbnail: %v", err)
 		}
 
 		// Generate thumbnail URL
-		thumbnailURL = fmt.Sprintf("%s/%s", s.publicURL, thumbnailKey)
+		thumbnailURL = s.backend.PublicURL(thumbnailKey)
 	}
 
 	// Create filename with final extension
@@ -289,33 +366,52 @@ func (s *StorageService) UploadImageWithThumbnail(file *multipart.FileHeader, fo
 	key := fmt.Sprintf("%s/%s", folder, filename)
 
 	// Upload final image (original for SVG, converted/original for others)
-	_, err = s.client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucketName),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(finalFileContent),
-		ContentType: aws.String(finalContentType),
-		ACL:         "public-read",
-	})
-	if err != nil {
+	if err := s.putObject(key, finalFileContent, finalContentType); err != nil {
 		return nil, fmt.Errorf("failed to upload image: %v", err)
 	}
 
 	// Generate public URL
-	imageURL := fmt.Sprintf("%s/%s", s.publicURL, key)
+	imageURL := s.backend.PublicURL(key)
+
+	// Responsive variants for srcset and loading placeholders; SVGs scale by themselves
+	var variants map[string]ImageVariant
+	var placeholder *ImagePlaceholder
+	if !isSVG {
+		if variants, err = s.uploadVariants(img, key); err != nil {
+			return nil, err
+		}
+		if placeholder, err = computePlaceholder(img); err != nil {
+			// The image is usable without a placeholder
+			fmt.Printf("Warning: Failed to compute image placeholder: %v\n", err)
+		}
+	}
 
 	response := &UploadResponse{
-		Success:  true,
-		FileURL:  imageURL,
-		FileSize: int64(len(finalFileContent)), // Use actual uploaded file size
+		Success:     true,
+		FileURL:     imageURL,
+		FileSize:    int64(len(finalFileContent)), // Use actual uploaded file size
+		Key:         key,
+		ContentType: finalContentType,
+		MediaType:   MediaTypeImage,
+	}
+	if panorama && !isSVG {
+		response.MediaType = MediaTypePanorama
 	}
 
 	// Only add thumbnail URL and dimensions for non-SVG files
 	if !isSVG {
 		response.ThumbnailURL = thumbnailURL
+		response.ThumbnailKey = thumbnailKey
 		response.Dimensions = &ImageDimensions{
 			Width:  width,
 			Height: height,
 		}
+		response.Variants = variants
+		response.SrcSet = BuildSrcSet(imageURL, width, variants)
+		response.ImagePlaceholder = placeholder
+		if exifData != nil && exifData.HasCaptureData() {
+			response.Exif = exifData
+		}
 	}
 
 	return response, nil
@@ -329,18 +425,34 @@ func (s *StorageService) DeleteImage(imageURL string) error {
 		return fmt.Errorf("invalid image URL")
 	}
 
-	_, err := s.client.DeleteObject(context.TODO(), &s3.DeleteObjectInput{
-		Bucket: aws.String(s.bucketName),
-		Key:    aws.String(key),
-	})
-	if err != nil {
+	return s.DeleteObject(key)
+}
+
+// DeleteObject deletes an object from storage by key
+func (s *StorageService) DeleteObject(key string) error {
+	if err := s.backend.Delete(context.TODO(), key); err != nil {
 		return fmt.Errorf("failed to delete file: %v", err)
 	}
+	s.usage.remove(key)
 
 	return nil
 }
 
-// DeleteImageWithThumbnail deletes both an image and its thumbnail from R2
+// ListObjects lists every object in storage
+func (s *StorageService) ListObjects() ([]StorageObject, error) {
+	objects, err := s.backend.List(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %v", err)
+	}
+	return objects, nil
+}
+
+// StatObject returns the metadata of an object, or ErrObjectNotFound
+func (s *StorageService) StatObject(key string) (*StorageObject, error) {
+	return s.backend.Stat(context.TODO(), key)
+}
+
+// DeleteImageWithThumbnail deletes an image together with its thumbnail and variants
 func (s *StorageService) DeleteImageWithThumbnail(imageURL string) error {
 	// Delete the original image
 	if err := s.DeleteImage(imageURL); err != nil {
@@ -357,10 +469,11 @@ func (s *StorageService) DeleteImageWithThumbnail(imageURL string) error {
 			// e.g., "image_abc123.jpg" -> "image_abc123_thumb.jpg"
 			baseKey := strings.TrimSuffix(key, ext)
 			thumbnailKey := fmt.Sprintf("%s_thumb%s", baseKey, ext)
-			thumbnailURL := fmt.Sprintf("%s/%s", s.publicURL, thumbnailKey)
+			thumbnailURL := s.backend.PublicURL(thumbnailKey)
 
 			// Try to delete thumbnail (ignore error if it doesn't exist)
 			_ = s.DeleteImage(thumbnailURL)
+			s.deleteVariants(key)
 		}
 	}
 
@@ -370,16 +483,21 @@ func (s *StorageService) DeleteImageWithThumbnail(imageURL string) error {
 // extractKeyFromURL extracts the object key from the full URL
 func (s *StorageService) extractKeyFromURL(imageURL string) string {
 	// Remove public URL prefix to get the key
-	prefix := fmt.Sprintf("%s/", s.publicURL)
+	prefix := s.backend.PublicURL("")
 	if strings.HasPrefix(imageURL, prefix) {
 		return strings.TrimPrefix(imageURL, prefix)
 	}
 	return ""
 }
 
-// IsR2URL checks if the given URL is from our R2 bucket
+// KeyFromURL returns the object key of a URL in our bucket, or "" for other URLs
+func (s *StorageService) KeyFromURL(imageURL string) string {
+	return s.extractKeyFromURL(imageURL)
+}
+
+// IsR2URL checks if the given URL is from our storage (the R2 bucket or the configured backend)
 func (s *StorageService) IsR2URL(imageURL string) bool {
-	return strings.HasPrefix(imageURL, s.publicURL)
+	return s.extractKeyFromURL(imageURL) != ""
 }
 
 // DeleteImageIfR2 deletes an image from R2 only if the URL is from our R2 bucket
@@ -408,8 +526,8 @@ func (s *StorageService) DeleteImageWithThumbnailIfR2(imageURL string) error {
 	return s.DeleteImageWithThumbnail(imageURL)
 }
 
-// getContentType returns the content type based on file extension
-func getContentType(filename string) string {
+// ContentTypeFromFilename returns the content type of a file based on its extension
+func ContentTypeFromFilename(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
 	switch ext {
 	case ".jpg", ".jpeg":
@@ -422,6 +540,10 @@ func getContentType(filename string) string {
 		return "image/webp"
 	case ".svg":
 		return "image/svg+xml"
+	case ".mp4":
+		return "video/mp4"
+	case ".webm":
+		return "video/webm"
 	default:
 		return "application/octet-stream"
 	}
@@ -429,7 +551,7 @@ func getContentType(filename string) string {
 
 // GenerateImageURL generates a properly formatted image URL
 func (s *StorageService) GenerateImageURL(key string) string {
-	return fmt.Sprintf("%s/%s", s.publicURL, key)
+	return s.backend.PublicURL(key)
 }
 
 // UploadResponse represents the response from image upload
@@ -439,6 +561,21 @@ type UploadResponse struct {
 	ThumbnailURL string           `json:"thumbnail_url,omitempty"`
 	FileSize     int64            `json:"file_size"`
 	Dimensions   *ImageDimensions `json:"dimensions,omitempty"`
+	Key          string           `json:"key"`
+	ThumbnailKey string           `json:"thumbnail_key,omitempty"`
+	ContentType  string           `json:"content_type"`
+
+	Variants map[string]ImageVariant `json:"variants,omitempty"` // Responsive sizes by variant name
+	SrcSet   string                  `json:"srcset,omitempty"`   // Ready-made srcset of the variants that keep the aspect ratio
+
+	*ImagePlaceholder // blurhash, lqip and dominant_color of raster images
+
+	Exif *ImageExif `json:"exif,omitempty"` // Capture data of photos; not present in the published file
+
+	MediaType       string          `json:"media_type"`                 // image, video or panorama
+	DurationSeconds float64         `json:"duration_seconds,omitempty"` // Videos only
+	Poster          *UploadResponse `json:"poster,omitempty"`           // Poster frame of a video, uploaded as an image
+	ContentHash     string          `json:"content_hash,omitempty"`     // SHA-256 of the file as uploaded
 }
 
 // ImageDimensions represents image dimensions
@@ -459,32 +596,21 @@ type StorageAnalytics struct {
 	CanUpload      bool    `json:"can_upload"`
 	RemainingBytes int64   `json:"remaining_bytes"`
 	RemainingMB    float64 `json:"remaining_mb"`
+
+	ByFolder      []UsageTotal `json:"by_folder"`
+	ByContentType []UsageTotal `json:"by_content_type"`
+	ReconciledAt  time.Time    `json:"reconciled_at"`
 }
 
-// GetStorageAnalytics retrieves storage usage analytics for the bucket
+// GetStorageAnalytics retrieves storage usage analytics for the bucket from the running usage
+// totals; see ReconcileUsage for a fresh listing
 func (s *StorageService) GetStorageAnalytics() (*StorageAnalytics, error) {
-	// List all objects in the bucket to calculate total size
-	var totalSize int64
-	var objectCount int64
-
-	// Use ListObjectsV2 to get all objects
-	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
-		Bucket: aws.String(s.bucketName),
-	})
-
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(context.TODO())
-		if err != nil {
-			return nil, fmt.Errorf("failed to list objects: %v", err)
-		}
-
-		for _, obj := range page.Contents {
-			if obj.Size != nil {
-				totalSize += *obj.Size
-			}
-			objectCount++
-		}
+	usage, err := s.Usage()
+	if err != nil {
+		return nil, err
 	}
+	totalSize := usage.TotalBytes
+	objectCount := usage.ObjectCount
 
 	// Get storage limit from config (default 1GB)
 	storageLimit := int64(appConfig.AppConfig.StorageLimitGB * 1024 * 1024 * 1024)
@@ -509,6 +635,10 @@ func (s *StorageService) GetStorageAnalytics() (*StorageAnalytics, error) {
 		CanUpload:      canUpload,
 		RemainingBytes: remainingBytes,
 		RemainingMB:    remainingMB,
+
+		ByFolder:      usage.ByFolder,
+		ByContentType: usage.ByContentType,
+		ReconciledAt:  usage.ReconciledAt,
 	}, nil
 }
 
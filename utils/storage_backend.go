@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Storage backends, selected with STORAGE_BACKEND
+const (
+	StorageBackendR2     = "r2"
+	StorageBackendLocal  = "local"
+	StorageBackendMemory = "memory"
+)
+
+// ErrObjectNotFound is returned by Get and Stat for keys that don't exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrPresignNotSupported is returned when the storage backend can't presign uploads
+var ErrPresignNotSupported = errors.New("presigned uploads are not supported by this storage backend")
+
+// StorageObject is an object in the bucket
+type StorageObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type,omitempty"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// StorageBackend stores objects by key and serves them at a public URL.
+// StorageService builds image processing and URL handling on top of it.
+type StorageBackend interface {
+	// Put stores an object, replacing any object with the same key
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	// Get returns the content of an object, or ErrObjectNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes an object; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// List returns every stored object
+	List(ctx context.Context) ([]StorageObject, error)
+	// Stat returns an object's metadata, or ErrObjectNotFound
+	Stat(ctx context.Context, key string) (*StorageObject, error)
+	// PublicURL returns the URL an object is served at. PublicURL("") is the prefix of every object URL.
+	PublicURL(key string) string
+}
+
+// PresignedUpload is a URL a client can PUT a file to directly, bypassing the API
+type PresignedUpload struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"` // Must be sent with the upload exactly as given
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// Presigner is implemented by backends that can hand out presigned upload URLs
+type Presigner interface {
+	// PresignPut returns a URL that accepts a single PUT of exactly size bytes of contentType at key
+	PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedUpload, error)
+}
+
+// joinPublicURL joins a base URL and an object key
+func joinPublicURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + key
+}
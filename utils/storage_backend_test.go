@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// testBackends returns every backend implementation, empty, for running the same tests against
+func testBackends(t *testing.T) map[string]StorageBackend {
+	t.Helper()
+	local, err := NewLocalBackend(t.TempDir(), "http://localhost:3000/storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]StorageBackend{
+		"memory": NewMemoryBackend("http://localhost:3000/storage"),
+		"local":  local,
+	}
+}
+
+func TestStorageBackends(t *testing.T) {
+	ctx := context.Background()
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			body := []byte("hello")
+			if err := backend.Put(ctx, "b/one.png", body, "image/png"); err != nil {
+				t.Fatal(err)
+			}
+			body[0] = 'j' // The backend keeps its own copy
+			if err := backend.Put(ctx, "a/two.png", []byte("hi"), "image/png"); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := backend.Get(ctx, "b/one.png")
+			if err != nil || string(got) != "hello" {
+				t.Errorf("Get() = %q, %v, want %q", got, err, "hello")
+			}
+
+			info, err := backend.Stat(ctx, "b/one.png")
+			if err != nil || info.Key != "b/one.png" || info.Size != 5 || info.ContentType != "image/png" {
+				t.Errorf("Stat() = %+v, %v", info, err)
+			}
+
+			objects, err := backend.List(ctx)
+			if err != nil || len(objects) != 2 || objects[0].Key != "a/two.png" || objects[1].Key != "b/one.png" {
+				t.Errorf("List() = %+v, %v, want both objects sorted by key", objects, err)
+			}
+
+			if url := backend.PublicURL("a/two.png"); url != "http://localhost:3000/storage/a/two.png" {
+				t.Errorf("PublicURL() = %q", url)
+			}
+
+			if err := backend.Delete(ctx, "b/one.png"); err != nil {
+				t.Fatal(err)
+			}
+			if err := backend.Delete(ctx, "b/one.png"); err != nil {
+				t.Errorf("Delete() of a missing object = %v, want nil", err)
+			}
+			if _, err := backend.Get(ctx, "b/one.png"); !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("Get() after Delete error = %v, want ErrObjectNotFound", err)
+			}
+			if _, err := backend.Stat(ctx, "b/one.png"); !errors.Is(err, ErrObjectNotFound) {
+				t.Errorf("Stat() after Delete error = %v, want ErrObjectNotFound", err)
+			}
+		})
+	}
+}
+
+func TestLocalBackendKeepsKeysInsideRoot(t *testing.T) {
+	root := t.TempDir()
+	backend, err := NewLocalBackend(root, "http://localhost:3000/storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Put(context.Background(), "../../escape.png", []byte("x"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+	objects, err := backend.List(context.Background())
+	if err != nil || len(objects) != 1 || objects[0].Key != "escape.png" {
+		t.Errorf("List() = %+v, %v, want the file stored under the root", objects, err)
+	}
+}
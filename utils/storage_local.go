@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// LocalBackend stores objects as files under a directory, for development without an R2 bucket.
+// The directory is served by Fiber's static handler at the path of the public URL.
+type LocalBackend struct {
+	root      string
+	publicURL string
+}
+
+// NewLocalBackend creates a backend storing files under root
+func NewLocalBackend(root, publicURL string) (*LocalBackend, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+	return &LocalBackend{root: root, publicURL: publicURL}, nil
+}
+
+// path returns the file of a key; keys can't escape the root directory
+func (b *LocalBackend) path(key string) string {
+	return filepath.Join(b.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put writes an object to disk. The file is written next to its destination and renamed
+// so the static handler never serves a partially written file.
+func (b *LocalBackend) Put(_ context.Context, key string, body []byte, _ string) error {
+	filename := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Get reads an object from disk
+func (b *LocalBackend) Get(_ context.Context, key string) ([]byte, error) {
+	body, err := os.ReadFile(b.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return body, err
+}
+
+// Delete removes an object from disk
+func (b *LocalBackend) Delete(_ context.Context, key string) error {
+	if err := os.Remove(b.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List walks the storage directory
+func (b *LocalBackend) List(_ context.Context) ([]StorageObject, error) {
+	var objects []StorageObject
+
+	err := filepath.WalkDir(b.root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || path.Base(filename)[0] == '.' {
+			return nil // Skip directories and files still being written
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(b.root, filename)
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, StorageObject{
+			Key:          filepath.ToSlash(rel),
+			Size:         info.Size(),
+			ContentType:  ContentTypeFromFilename(rel),
+			LastModified: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// Stat returns the metadata of a file
+func (b *LocalBackend) Stat(_ context.Context, key string) (*StorageObject, error) {
+	info, err := os.Stat(b.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrObjectNotFound
+	}
+
+	return &StorageObject{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  ContentTypeFromFilename(key),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// PublicURL returns the URL the static handler serves a file at
+func (b *LocalBackend) PublicURL(key string) string {
+	return joinPublicURL(b.publicURL, key)
+}
+
+// LocalStoragePath returns the route prefix the local storage directory is served at,
+// taken from the path of its public URL
+func LocalStoragePath(publicURL string) string {
+	parsed, err := url.Parse(publicURL)
+	if err != nil {
+		return "/storage"
+	}
+	return path.Clean("/" + parsed.Path)
+}
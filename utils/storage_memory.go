@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps objects in memory. Meant for tests; nothing serves its URLs.
+type MemoryBackend struct {
+	mu        sync.RWMutex
+	objects   map[string]memoryObject
+	publicURL string
+}
+
+type memoryObject struct {
+	body         []byte
+	contentType  string
+	lastModified time.Time
+}
+
+// NewMemoryBackend creates an empty in-memory backend
+func NewMemoryBackend(publicURL string) *MemoryBackend {
+	return &MemoryBackend{objects: map[string]memoryObject{}, publicURL: publicURL}
+}
+
+// Put stores a copy of body
+func (b *MemoryBackend) Put(_ context.Context, key string, body []byte, contentType string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.objects[key] = memoryObject{
+		body:         append([]byte(nil), body...),
+		contentType:  contentType,
+		lastModified: time.Now(),
+	}
+	return nil
+}
+
+// Delete removes an object
+func (b *MemoryBackend) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.objects, key)
+	return nil
+}
+
+// List returns every object, sorted by key
+func (b *MemoryBackend) List(_ context.Context) ([]StorageObject, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	objects := make([]StorageObject, 0, len(b.objects))
+	for key, obj := range b.objects {
+		objects = append(objects, obj.info(key))
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Stat returns the metadata of an object
+func (b *MemoryBackend) Stat(_ context.Context, key string) (*StorageObject, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	info := obj.info(key)
+	return &info, nil
+}
+
+// PublicURL returns the URL an object would be served at
+func (b *MemoryBackend) PublicURL(key string) string {
+	return joinPublicURL(b.publicURL, key)
+}
+
+// Get returns a copy of an object's content
+func (b *MemoryBackend) Get(_ context.Context, key string) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return append([]byte(nil), obj.body...), nil
+}
+
+func (obj memoryObject) info(key string) StorageObject {
+	return StorageObject{
+		Key:          key,
+		Size:         int64(len(obj.body)),
+		ContentType:  obj.contentType,
+		LastModified: obj.lastModified,
+	}
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// R2Backend stores objects in a Cloudflare R2 bucket through its S3 API
+type R2Backend struct {
+	client     *s3.Client
+	bucketName string
+	publicURL  string // Public URL for file access (r2.dev or custom domain)
+}
+
+// NewR2Backend creates a backend for an R2 bucket
+func NewR2Backend(endpoint, region, accessKey, secretKey, bucketName, publicURL string) (*R2Backend, error) {
+	if endpoint == "" {
+		return nil, errors.New("R2_ENDPOINT is not configured")
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithRegion(region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load SDK config: %v", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+	})
+
+	return &R2Backend{
+		client:     client,
+		bucketName: bucketName,
+		publicURL:  publicURL,
+	}, nil
+}
+
+// Put uploads an object, publicly readable
+func (b *R2Backend) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.bucketName),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+		ACL:         "public-read", // Make publicly accessible
+	})
+	return err
+}
+
+// Get downloads an object from the bucket
+func (b *R2Backend) Get(ctx context.Context, key string) ([]byte, error) {
+	object, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	return io.ReadAll(object.Body)
+}
+
+// Delete deletes an object from the bucket
+func (b *R2Backend) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.bucketName),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// List lists every object in the bucket
+func (b *R2Backend) List(ctx context.Context) ([]StorageObject, error) {
+	var objects []StorageObject
+
+	paginator := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucketName),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range page.Contents {
+			object := StorageObject{Key: aws.ToString(obj.Key)}
+			if obj.Size != nil {
+				object.Size = *obj.Size
+			}
+			if obj.LastModified != nil {
+				object.LastModified = *obj.LastModified
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	return objects, nil
+}
+
+// Stat returns the metadata of an object in the bucket
+func (b *R2Backend) Stat(ctx context.Context, key string) (*StorageObject, error) {
+	head, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.bucketName),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	object := &StorageObject{Key: key, ContentType: aws.ToString(head.ContentType)}
+	if head.ContentLength != nil {
+		object.Size = *head.ContentLength
+	}
+	if head.LastModified != nil {
+		object.LastModified = *head.LastModified
+	}
+	return object, nil
+}
+
+// PresignPut presigns a PutObject request. Content type and length are part of the
+// signature, so R2 rejects uploads of any other type or size.
+func (b *R2Backend) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedUpload, error) {
+	request, err := s3.NewPresignClient(b.client).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(b.bucketName),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(expires))
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{}
+	for name := range request.SignedHeader {
+		// The client sets Host itself
+		if name == "Host" {
+			continue
+		}
+		headers[name] = request.SignedHeader.Get(name)
+	}
+
+	return &PresignedUpload{
+		URL:       request.URL,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: time.Now().Add(expires),
+	}, nil
+}
+
+// PublicURL returns the public URL of an object
+func (b *R2Backend) PublicURL(key string) string {
+	return fmt.Sprintf("%s/%s", b.publicURL, key)
+}
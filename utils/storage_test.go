@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadImageContent(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			service := NewStorageService(backend)
+
+			result, err := service.UploadImageContent(testPNG(t, 800, 600), "Holiday Photo.png", "gallery")
+			if err != nil {
+				t.Fatalf("UploadImageContent() error = %v", err)
+			}
+			if !strings.HasPrefix(result.Key, "gallery/Holiday-Photo_") || !strings.HasSuffix(result.Key, ".webp") {
+				t.Errorf("Key = %q, want gallery/Holiday-Photo_<id>.webp", result.Key)
+			}
+			if result.FileURL != backend.PublicURL(result.Key) {
+				t.Errorf("FileURL = %q, want the public URL of the key", result.FileURL)
+			}
+			if result.Dimensions == nil || result.Dimensions.Width != 800 || result.Dimensions.Height != 600 {
+				t.Errorf("Dimensions = %+v, want 800x600", result.Dimensions)
+			}
+
+			for _, key := range []string{result.Key, result.ThumbnailKey, result.Variants["sm"].Key} {
+				if _, err := backend.Stat(context.Background(), key); err != nil {
+					t.Errorf("object %q was not stored: %v", key, err)
+				}
+			}
+
+			if err := service.DeleteImageWithThumbnail(result.FileURL); err != nil {
+				t.Fatalf("DeleteImageWithThumbnail() error = %v", err)
+			}
+			if objects, _ := backend.List(context.Background()); len(objects) != 0 {
+				t.Errorf("objects left after delete: %+v", objects)
+			}
+		})
+	}
+}
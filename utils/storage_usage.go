@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UsageTotal is the number and size of stored objects in one group of a usage breakdown
+type UsageTotal struct {
+	Name        string `json:"name"` // Folder or content type
+	Bytes       int64  `json:"bytes"`
+	ObjectCount int64  `json:"object_count"`
+}
+
+// StorageUsage is the current storage use, broken down by top-level folder and content type
+type StorageUsage struct {
+	TotalBytes    int64        `json:"total_bytes"`
+	ObjectCount   int64        `json:"object_count"`
+	ByFolder      []UsageTotal `json:"by_folder"`
+	ByContentType []UsageTotal `json:"by_content_type"`
+	ReconciledAt  time.Time    `json:"reconciled_at"` // Last full listing of the bucket
+	Drift         int64        `json:"drift_bytes"`   // Correction the last listing made to the running total
+}
+
+// usageTracker keeps running storage totals. It is seeded from a full listing, follows every
+// upload and deletion made through StorageService and is reconciled with a new listing now and
+// then, to pick up changes made elsewhere (presigned uploads, other instances, the dashboard).
+// The totals are counted as objects come and go, so reading them doesn't walk every object.
+type usageTracker struct {
+	mu            sync.Mutex
+	objects       map[string]trackedObject // By key; nil until the first reconciliation
+	totalBytes    int64
+	byFolder      map[string]*UsageTotal
+	byContentType map[string]*UsageTotal
+	reconciledAt  time.Time
+	drift         int64
+
+	// Changes made while the bucket is being listed, by key, applied on top of the listing
+	// since it may or may not include them. Removals are recorded as nil.
+	listings int
+	pending  map[string]*trackedObject
+
+	// The reconciliation in progress; concurrent callers wait for it instead of listing again
+	reconciling *usageReconciliation
+}
+
+// usageReconciliation is a full listing of the bucket shared by everyone who asks for one
+// while it runs. done is closed once usage and err are set.
+type usageReconciliation struct {
+	done  chan struct{}
+	usage *StorageUsage
+	err   error
+}
+
+type trackedObject struct {
+	size        int64
+	contentType string
+}
+
+func (u *usageTracker) put(key string, size int64, contentType string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	object := trackedObject{size: size, contentType: usageContentType(key, contentType)}
+	if u.pending != nil {
+		u.pending[key] = &object
+	}
+	if u.objects != nil {
+		u.untrack(key)
+		u.track(key, object)
+	}
+}
+
+func (u *usageTracker) remove(key string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.pending != nil {
+		u.pending[key] = nil
+	}
+	u.untrack(key)
+}
+
+// track adds an object to the totals; the caller holds the lock and has untracked the key
+func (u *usageTracker) track(key string, object trackedObject) {
+	u.objects[key] = object
+	u.totalBytes += object.size
+	addUsage(u.byFolder, usageFolder(key), object.size, 1)
+	addUsage(u.byContentType, object.contentType, object.size, 1)
+}
+
+// untrack removes an object from the totals; the caller holds the lock
+func (u *usageTracker) untrack(key string) {
+	object, ok := u.objects[key]
+	if !ok {
+		return
+	}
+	delete(u.objects, key)
+	u.totalBytes -= object.size
+	addUsage(u.byFolder, usageFolder(key), -object.size, -1)
+	addUsage(u.byContentType, object.contentType, -object.size, -1)
+}
+
+// beginListing starts recording changes for reset; every call must be followed by reset or endListing
+func (u *usageTracker) beginListing() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.listings == 0 {
+		u.pending = map[string]*trackedObject{}
+	}
+	u.listings++
+}
+
+// endListing stops recording changes once no listing is in progress anymore
+func (u *usageTracker) endListing() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.finishListing()
+}
+
+func (u *usageTracker) finishListing() {
+	u.listings--
+	if u.listings == 0 {
+		u.pending = nil
+	}
+}
+
+// reset replaces the totals with a listing that started with beginListing. Uploads and
+// deletions made while it was listed are applied on top.
+func (u *usageTracker) reset(objects []StorageObject) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	previous, ready := u.totalBytes, u.objects != nil
+	u.objects = make(map[string]trackedObject, len(objects))
+	u.totalBytes = 0
+	u.byFolder = map[string]*UsageTotal{}
+	u.byContentType = map[string]*UsageTotal{}
+	for _, object := range objects {
+		u.untrack(object.Key)
+		u.track(object.Key, trackedObject{size: object.Size, contentType: usageContentType(object.Key, object.ContentType)})
+	}
+	for key, object := range u.pending {
+		u.untrack(key)
+		if object != nil {
+			u.track(key, *object)
+		}
+	}
+	u.finishListing()
+
+	u.drift = 0
+	if ready {
+		u.drift = u.totalBytes - previous
+	}
+	u.reconciledAt = time.Now()
+}
+
+// startReconciliation returns the reconciliation in progress, or starts one when none is and
+// reports that the caller has to run it
+func (u *usageTracker) startReconciliation() (*usageReconciliation, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.reconciling != nil {
+		return u.reconciling, false
+	}
+	u.reconciling = &usageReconciliation{done: make(chan struct{})}
+	return u.reconciling, true
+}
+
+// finishReconciliation hands the result to the callers waiting for it
+func (u *usageTracker) finishReconciliation(reconciliation *usageReconciliation, usage *StorageUsage, err error) {
+	u.mu.Lock()
+	u.reconciling = nil
+	u.mu.Unlock()
+
+	reconciliation.usage, reconciliation.err = usage, err
+	close(reconciliation.done)
+}
+
+func (u *usageTracker) ready() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.objects != nil
+}
+
+func (u *usageTracker) usage() *StorageUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return &StorageUsage{
+		TotalBytes:    u.totalBytes,
+		ObjectCount:   int64(len(u.objects)),
+		ByFolder:      sortedUsage(u.byFolder),
+		ByContentType: sortedUsage(u.byContentType),
+		ReconciledAt:  u.reconciledAt,
+		Drift:         u.drift,
+	}
+}
+
+// usageFolder is the top-level folder of a key: "gallery/photo_ab12cd34.webp" is in "gallery"
+func usageFolder(key string) string {
+	if i := strings.Index(key, "/"); i > 0 {
+		return key[:i]
+	}
+	return "/"
+}
+
+// usageContentType falls back to the extension for listings that don't carry content types
+func usageContentType(key, contentType string) string {
+	if contentType != "" {
+		return contentType
+	}
+	return ContentTypeFromFilename(key)
+}
+
+// addUsage adds bytes and objects to a group of totals, dropping groups that become empty
+func addUsage(totals map[string]*UsageTotal, name string, bytes, objects int64) {
+	total := totals[name]
+	if total == nil {
+		total = &UsageTotal{Name: name}
+		totals[name] = total
+	}
+	total.Bytes += bytes
+	total.ObjectCount += objects
+	if total.ObjectCount <= 0 {
+		delete(totals, name)
+	}
+}
+
+// sortedUsage returns the totals largest first
+func sortedUsage(totals map[string]*UsageTotal) []UsageTotal {
+	sorted := make([]UsageTotal, 0, len(totals))
+	for _, total := range totals {
+		sorted = append(sorted, *total)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Bytes != sorted[j].Bytes {
+			return sorted[i].Bytes > sorted[j].Bytes
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// putObject stores an object and adds it to the running usage totals
+func (s *StorageService) putObject(key string, body []byte, contentType string) error {
+	if err := s.backend.Put(context.TODO(), key, body, contentType); err != nil {
+		return err
+	}
+	s.usage.put(key, int64(len(body)), contentType)
+	return nil
+}
+
+// ReconcileUsage replaces the running usage totals with a full listing of the bucket. Uploads
+// and deletions made through the service while the bucket is listed are kept. Calls made while
+// a listing is running share its result, so the first Usage call and the reconcile job don't
+// both list the bucket.
+func (s *StorageService) ReconcileUsage() (*StorageUsage, error) {
+	reconciliation, first := s.usage.startReconciliation()
+	if !first {
+		<-reconciliation.done
+		return reconciliation.usage, reconciliation.err
+	}
+
+	usage, err := s.listUsage()
+	s.usage.finishReconciliation(reconciliation, usage, err)
+	return usage, err
+}
+
+// listUsage lists the bucket and resets the running totals with it
+func (s *StorageService) listUsage() (*StorageUsage, error) {
+	s.usage.beginListing()
+	objects, err := s.ListObjects()
+	if err != nil {
+		s.usage.endListing()
+		return nil, err
+	}
+	s.usage.reset(objects)
+	return s.usage.usage(), nil
+}
+
+// Usage returns the running usage totals. Only the first call lists the bucket.
+func (s *StorageService) Usage() (*StorageUsage, error) {
+	if !s.usage.ready() {
+		return s.ReconcileUsage()
+	}
+	return s.usage.usage(), nil
+}
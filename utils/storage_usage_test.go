@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUsageTrackerCounts(t *testing.T) {
+	u := &usageTracker{}
+	u.beginListing()
+	u.reset([]StorageObject{
+		{Key: "gallery/a.webp", Size: 100, ContentType: "image/webp"},
+		{Key: "news/b.webp", Size: 50, ContentType: "image/webp"},
+	})
+
+	u.put("news/c.mp4", 1000, "video/mp4")
+	u.put("gallery/a.webp", 120, "image/webp") // Overwritten
+	u.remove("news/b.webp")
+	u.remove("news/missing.webp")
+
+	usage := u.usage()
+	if usage.TotalBytes != 1120 || usage.ObjectCount != 2 {
+		t.Errorf("total = %d bytes in %d objects, want 1120 in 2", usage.TotalBytes, usage.ObjectCount)
+	}
+	wantFolders := []UsageTotal{{Name: "news", Bytes: 1000, ObjectCount: 1}, {Name: "gallery", Bytes: 120, ObjectCount: 1}}
+	if !reflect.DeepEqual(usage.ByFolder, wantFolders) {
+		t.Errorf("ByFolder = %+v, want %+v", usage.ByFolder, wantFolders)
+	}
+	wantTypes := []UsageTotal{{Name: "video/mp4", Bytes: 1000, ObjectCount: 1}, {Name: "image/webp", Bytes: 120, ObjectCount: 1}}
+	if !reflect.DeepEqual(usage.ByContentType, wantTypes) {
+		t.Errorf("ByContentType = %+v, want %+v", usage.ByContentType, wantTypes)
+	}
+}
+
+func TestUsageTrackerKeepsChangesDuringListing(t *testing.T) {
+	u := &usageTracker{}
+	u.beginListing()
+	u.reset([]StorageObject{{Key: "gallery/old.webp", Size: 10}})
+
+	// The bucket is listed while an upload and a deletion happen; the listing saw neither
+	u.beginListing()
+	u.put("gallery/new.webp", 30, "image/webp")
+	u.remove("gallery/old.webp")
+	u.reset([]StorageObject{{Key: "gallery/old.webp", Size: 10}, {Key: "gallery/external.webp", Size: 5}})
+
+	usage := u.usage()
+	if usage.TotalBytes != 35 || usage.ObjectCount != 2 {
+		t.Errorf("total = %d bytes in %d objects, want 35 in 2", usage.TotalBytes, usage.ObjectCount)
+	}
+	if usage.Drift != 5 { // Only the object uploaded elsewhere was missing
+		t.Errorf("Drift = %d, want 5", usage.Drift)
+	}
+	if u.pending != nil {
+		t.Errorf("changes are still recorded after the listing: %v", u.pending)
+	}
+}
+
+func TestUsageTrackerOverlappingListings(t *testing.T) {
+	u := &usageTracker{}
+	u.beginListing()
+	u.beginListing()
+	u.put("a.webp", 1, "")
+	u.reset(nil) // The first listing finishes
+	u.put("b.webp", 2, "")
+	u.reset(nil) // The second one still keeps both uploads
+
+	if usage := u.usage(); usage.TotalBytes != 3 || usage.ObjectCount != 2 {
+		t.Errorf("total = %d bytes in %d objects, want 3 in 2", usage.TotalBytes, usage.ObjectCount)
+	}
+}
+
+func TestUsageTrackerSharesReconciliation(t *testing.T) {
+	u := &usageTracker{}
+	running, first := u.startReconciliation()
+	if !first {
+		t.Fatal("first caller has to run the reconciliation")
+	}
+	joined, first := u.startReconciliation()
+	if first || joined != running {
+		t.Fatal("a caller during the reconciliation should wait for it instead of listing again")
+	}
+
+	failed := errors.New("listing failed")
+	u.finishReconciliation(running, nil, failed)
+	<-joined.done
+	if joined.err != failed {
+		t.Errorf("waiting caller got %v, want the error of the listing", joined.err)
+	}
+
+	if _, first := u.startReconciliation(); !first {
+		t.Error("a caller after the reconciliation should start a new one")
+	}
+}
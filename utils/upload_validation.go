@@ -0,0 +1,304 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultUploadTypes are the content types accepted for upload unless UPLOAD_ALLOWED_TYPES says otherwise
+const DefaultUploadTypes = "image/jpeg,image/png,image/webp,image/gif,image/svg+xml,video/mp4,video/webm"
+
+// DefaultImageMaxPixels is the largest image (width × height) accepted for upload. Decoding
+// takes 4 bytes per pixel, so this bounds the memory a small, highly compressed file can claim.
+const DefaultImageMaxPixels = 50_000_000
+
+// Errors returned for rejected uploads; the message wrapping them says why
+var (
+	ErrFileTypeNotAllowed = errors.New("file type not allowed")
+	ErrImageTooLarge      = errors.New("image dimensions too large")
+	ErrInvalidFolder      = errors.New("invalid folder")
+)
+
+// ParseUploadTypes parses a comma-separated list of content types, e.g. "image/png,image/webp"
+func ParseUploadTypes(spec string) (map[string]bool, error) {
+	types := map[string]bool{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			return nil, fmt.Errorf("%q is not a content type such as image/png", entry)
+		}
+		types[entry] = true
+	}
+	if len(types) == 0 {
+		return nil, errors.New("no content types given")
+	}
+	return types, nil
+}
+
+// UploadTypeAllowed reports whether files of a content type may be uploaded
+func (s *StorageService) UploadTypeAllowed(contentType string) bool {
+	return s.uploadTypes[contentType]
+}
+
+// checkUpload detects the real type of a file from its first bytes and returns it when the
+// type is on the allow-list and the extension of the filename matches it
+func (s *StorageService) checkUpload(content []byte, filename string) (string, error) {
+	contentType := DetectContentType(content)
+	if contentType == "" {
+		return "", fmt.Errorf("%w: the file is not a recognised image or video", ErrFileTypeNotAllowed)
+	}
+	if !s.UploadTypeAllowed(contentType) {
+		return "", fmt.Errorf("%w: %s files can't be uploaded", ErrFileTypeNotAllowed, contentType)
+	}
+	if ContentTypeFromFilename(filename) != contentType {
+		return "", fmt.Errorf("%w: the file is %s but its name ends in %q", ErrFileTypeNotAllowed, contentType, filepath.Ext(filename))
+	}
+	return contentType, nil
+}
+
+// checkImagePixels rejects images whose dimensions exceed the pixel limit, before they are decoded
+func (s *StorageService) checkImagePixels(width, height int) error {
+	if s.maxPixels > 0 && int64(width)*int64(height) > int64(s.maxPixels) {
+		return fmt.Errorf("%w: %dx%d is more than %d megapixels", ErrImageTooLarge, width, height, s.maxPixels/1_000_000)
+	}
+	return nil
+}
+
+// DetectContentType returns the content type of a file from its magic bytes, or "" when it is
+// none of the supported image and video formats
+func DetectContentType(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(content, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(content, []byte("GIF87a")), bytes.HasPrefix(content, []byte("GIF89a")):
+		return "image/gif"
+	case len(content) >= 12 && string(content[0:4]) == "RIFF" && string(content[8:12]) == "WEBP":
+		return "image/webp"
+	case len(content) >= 12 && string(content[4:8]) == "ftyp":
+		return "video/mp4"
+	case bytes.HasPrefix(content, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "video/webm"
+	case isSVGContent(content):
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// isSVGContent reports whether a file is UTF-8 XML whose root element is <svg>
+func isSVGContent(content []byte) bool {
+	if !utf8.Valid(content) {
+		return false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return strings.EqualFold(start.Name.Local, "svg")
+		}
+	}
+}
+
+// folderPattern allows up to three levels of lowercase names, e.g. "gallery" or "news/2024"
+var folderPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}(/[a-z0-9][a-z0-9_-]{0,63}){0,2}$`)
+
+// ValidateFolder checks the folder an upload is stored in. Folders become part of the object
+// key, so they are limited to simple names; "incoming" is reserved for presigned uploads.
+func ValidateFolder(folder string) error {
+	if !folderPattern.MatchString(folder) {
+		return fmt.Errorf("%w: use up to three lowercase names of letters, digits, - and _ separated by /", ErrInvalidFolder)
+	}
+	if folder == "incoming" || strings.HasPrefix(folder, "incoming/") {
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidFolder, "incoming")
+	}
+	return nil
+}
+
+// unsafeKeyChars are replaced in the filename part of object keys
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// keyFilename splits an uploaded filename into a base safe to use in object keys and its extension:
+// "../My Photo (1).JPG" becomes "My-Photo-1" and ".JPG"
+func keyFilename(filename string) (string, string) {
+	filename = filepath.Base(strings.ReplaceAll(filename, "\\", "/"))
+	ext := filepath.Ext(filename)
+	base := strings.Trim(unsafeKeyChars.ReplaceAllString(strings.TrimSuffix(filename, ext), "-"), "-.")
+	if base == "" {
+		base = "file"
+	}
+	if len(base) > 100 {
+		base = base[:100]
+	}
+	if unsafeKeyChars.MatchString(ext) {
+		ext = ""
+	}
+	return base, ext
+}
+
+// svgBlockedElements are removed from SVGs together with everything inside them
+var svgBlockedElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+	"audio":         true,
+	"video":         true,
+}
+
+// SanitizeSVG removes everything from an SVG that can run code or load other files: scripts,
+// embedded HTML, event handler attributes, links other than to fragments of the same file or
+// inline images, and CSS that imports or loads URLs. Comments, processing instructions and
+// DOCTYPE declarations (entities) are dropped as well.
+func SanitizeSVG(content []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.Strict = false
+
+	var out bytes.Buffer
+	out.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+
+	skipDepth := 0 // > 0 while inside a removed element
+	inStyle := false
+	sawRoot := false
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid SVG: %v", ErrFileTypeNotAllowed, err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !sawRoot {
+				if !strings.EqualFold(t.Name.Local, "svg") {
+					return nil, fmt.Errorf("%w: not an SVG file", ErrFileTypeNotAllowed)
+				}
+				sawRoot = true
+			}
+			if skipDepth > 0 || svgBlockedElements[strings.ToLower(t.Name.Local)] || isUnsafeAnimation(t) {
+				skipDepth++
+				continue
+			}
+			inStyle = strings.EqualFold(t.Name.Local, "style")
+
+			out.WriteString("<" + xmlName(t.Name))
+			for _, attr := range t.Attr {
+				if !svgAttrAllowed(attr) {
+					continue
+				}
+				out.WriteString(" " + xmlName(attr.Name) + `="`)
+				xml.EscapeText(&out, []byte(attr.Value))
+				out.WriteString(`"`)
+			}
+			out.WriteString(">")
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			inStyle = false
+			out.WriteString("</" + xmlName(t.Name) + ">")
+
+		case xml.CharData:
+			if skipDepth > 0 || (inStyle && unsafeCSS(string(t))) {
+				continue
+			}
+			xml.EscapeText(&out, t)
+		}
+	}
+
+	if !sawRoot {
+		return nil, fmt.Errorf("%w: not an SVG file", ErrFileTypeNotAllowed)
+	}
+	return out.Bytes(), nil
+}
+
+// svgAttrAllowed reports whether an attribute is free of scripts and external references
+func svgAttrAllowed(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	value := strings.ToLower(strings.Join(strings.Fields(attr.Value), ""))
+
+	switch {
+	case strings.HasPrefix(name, "on"):
+		return false
+	case name == "href" || name == "src":
+		return strings.HasPrefix(value, "#") || isInlineImage(value)
+	case name == "style":
+		return !unsafeCSS(value)
+	}
+	return !strings.Contains(value, "javascript:") && !unsafeCSS(value)
+}
+
+// isUnsafeAnimation reports whether an animation element would change a link or event handler
+// once the file is displayed, e.g. <set attributeName="href" to="javascript:...">
+func isUnsafeAnimation(start xml.StartElement) bool {
+	switch strings.ToLower(start.Name.Local) {
+	case "set", "animate", "animatetransform", "animatemotion":
+	default:
+		return false
+	}
+	for _, attr := range start.Attr {
+		if strings.EqualFold(attr.Name.Local, "attributeName") {
+			target := strings.ToLower(attr.Value)
+			return strings.HasSuffix(target, "href") || strings.HasPrefix(target, "on")
+		}
+	}
+	return false
+}
+
+// unsafeCSS reports whether CSS imports files, loads URLs other than fragments and inline images,
+// or runs script
+func unsafeCSS(css string) bool {
+	css = strings.ToLower(strings.Join(strings.Fields(css), ""))
+	if strings.Contains(css, "@import") || strings.Contains(css, "expression(") || strings.Contains(css, "javascript:") {
+		return true
+	}
+	for rest := css; ; {
+		i := strings.Index(rest, "url(")
+		if i < 0 {
+			return false
+		}
+		target := strings.TrimLeft(rest[i+len("url("):], `'"`)
+		if !strings.HasPrefix(target, "#") && !isInlineImage(target) {
+			return true
+		}
+		rest = rest[i+len("url("):]
+	}
+}
+
+// isInlineImage reports whether a URL is a base64 data URI of a raster image
+func isInlineImage(value string) bool {
+	for _, prefix := range []string{"data:image/png;", "data:image/jpeg;", "data:image/gif;", "data:image/webp;"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+	return name.Local
+}
@@ -0,0 +1,230 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"jpeg", "\xFF\xD8\xFF\xE0rest", "image/jpeg"},
+		{"png", "\x89PNG\r\n\x1a\nrest", "image/png"},
+		{"gif87a", "GIF87a...", "image/gif"},
+		{"gif89a", "GIF89a...", "image/gif"},
+		{"webp", "RIFF\x00\x00\x00\x00WEBPVP8 ", "image/webp"},
+		{"mp4", "\x00\x00\x00\x18ftypmp42", "video/mp4"},
+		{"webm", "\x1A\x45\xDF\xA3rest", "video/webm"},
+		{"svg", `<svg xmlns="http://www.w3.org/2000/svg"></svg>`, "image/svg+xml"},
+		{"svg with prolog", "<?xml version=\"1.0\"?>\n<!-- logo -->\n<svg></svg>", "image/svg+xml"},
+		{"html", "<html><body></body></html>", ""},
+		{"riff but not webp", "RIFF\x00\x00\x00\x00WAVEfmt ", ""},
+		{"text", "hello", ""},
+		{"empty", "", ""},
+		{"invalid utf-8", "<svg>\xff</svg>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContentType([]byte(tt.content)); got != tt.want {
+				t.Errorf("DetectContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFolder(t *testing.T) {
+	tests := []struct {
+		folder string
+		valid  bool
+	}{
+		{"gallery", true},
+		{"news/2024", true},
+		{"a/b/c", true},
+		{"media_assets-1", true},
+		{"", false},
+		{"a/b/c/d", false},
+		{"News", false},
+		{"../etc", false},
+		{"news/../secrets", false},
+		{"/gallery", false},
+		{"gallery/", false},
+		{"-gallery", false},
+		{"my folder", false},
+		{"incoming", false},
+		{"incoming/x", false},
+		{"incomings", true},
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 65), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.folder, func(t *testing.T) {
+			err := ValidateFolder(tt.folder)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateFolder(%q) = %v, want nil", tt.folder, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidFolder) {
+				t.Errorf("ValidateFolder(%q) = %v, want ErrInvalidFolder", tt.folder, err)
+			}
+		})
+	}
+}
+
+func TestKeyFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		base     string
+		ext      string
+	}{
+		{"photo.jpg", "photo", ".jpg"},
+		{"../My Photo (1).JPG", "My-Photo-1", ".JPG"},
+		{`C:\Users\me\pic.png`, "pic", ".png"},
+		{"!!!.png", "file", ".png"},
+		{"émoji 🎉.webp", "moji", ".webp"},
+		{"noext", "noext", ""},
+		{"weird.ext x", "weird", ""},
+		{strings.Repeat("a", 150) + ".png", strings.Repeat("a", 100), ".png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			base, ext := keyFilename(tt.filename)
+			if base != tt.base || ext != tt.ext {
+				t.Errorf("keyFilename(%q) = %q, %q, want %q, %q", tt.filename, base, ext, tt.base, tt.ext)
+			}
+		})
+	}
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name    string
+		svg     string
+		keep    []string
+		removed []string
+	}{
+		{
+			name:    "script element",
+			svg:     `<svg><script>alert(1)</script><circle r="1"/></svg>`,
+			keep:    []string{`<circle r="1">`},
+			removed: []string{"script", "alert"},
+		},
+		{
+			name:    "event handlers",
+			svg:     `<svg onload="alert(1)"><rect onclick="x()" width="2"/></svg>`,
+			keep:    []string{`width="2"`},
+			removed: []string{"onload", "onclick", "alert"},
+		},
+		{
+			name:    "javascript links",
+			svg:     `<svg><a href="javascript:alert(1)"><text>x</text></a></svg>`,
+			keep:    []string{"<text>x</text>"},
+			removed: []string{"javascript"},
+		},
+		{
+			name:    "external references",
+			svg:     `<svg xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="https://evil.example/x.png"/><use href="#icon"/></svg>`,
+			keep:    []string{`href="#icon"`},
+			removed: []string{"evil.example"},
+		},
+		{
+			name:    "inline images",
+			svg:     `<svg><image href="data:image/png;base64,AAAA"/><image href="data:text/html;base64,AAAA"/></svg>`,
+			keep:    []string{"data:image/png;base64,AAAA"},
+			removed: []string{"data:text/html"},
+		},
+		{
+			name:    "embedded html",
+			svg:     `<svg><foreignObject><iframe src="https://evil.example"></iframe></foreignObject><g/></svg>`,
+			keep:    []string{"<g>"},
+			removed: []string{"foreignObject", "iframe", "evil.example"},
+		},
+		{
+			name:    "css loading urls",
+			svg:     `<svg><style>@import url(https://evil.example/x.css);</style><rect style="fill: url(https://evil.example/p)"/><rect style="fill: red"/></svg>`,
+			keep:    []string{`style="fill: red"`},
+			removed: []string{"@import", "evil.example"},
+		},
+		{
+			name:    "animated links",
+			svg:     `<svg><a><set attributeName="href" to="javascript:alert(1)"/></a><animate attributeName="opacity"/></svg>`,
+			keep:    []string{`attributeName="opacity"`},
+			removed: []string{"<set", "javascript"},
+		},
+		{
+			name:    "entities and comments",
+			svg:     `<!DOCTYPE svg [<!ENTITY x "boom">]><svg><!-- secret --><text>ok</text></svg>`,
+			keep:    []string{"<text>ok</text>"},
+			removed: []string{"ENTITY", "secret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := SanitizeSVG([]byte(tt.svg))
+			if err != nil {
+				t.Fatalf("SanitizeSVG() error = %v", err)
+			}
+			for _, s := range tt.keep {
+				if !strings.Contains(string(out), s) {
+					t.Errorf("SanitizeSVG() = %s, want it to keep %q", out, s)
+				}
+			}
+			for _, s := range tt.removed {
+				if strings.Contains(string(out), s) {
+					t.Errorf("SanitizeSVG() = %s, want %q removed", out, s)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeSVGRejectsOtherFiles(t *testing.T) {
+	for _, content := range []string{`<html><svg/></html>`, "", "not xml at all <"} {
+		if _, err := SanitizeSVG([]byte(content)); !errors.Is(err, ErrFileTypeNotAllowed) {
+			t.Errorf("SanitizeSVG(%q) error = %v, want ErrFileTypeNotAllowed", content, err)
+		}
+	}
+}
+
+func TestUploadImageContentSanitizesSVG(t *testing.T) {
+	backend := NewMemoryBackend("https://cdn.example.com/media")
+	service := NewStorageService(backend)
+
+	result, err := service.UploadImageContent([]byte(`<svg onload="alert(1)"><circle r="1"/></svg>`), "logo.svg", "icons")
+	if err != nil {
+		t.Fatalf("UploadImageContent() error = %v", err)
+	}
+	stored, err := backend.Get(context.Background(), result.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(stored), "onload") {
+		t.Errorf("stored SVG = %s, want event handlers removed", stored)
+	}
+}
+
+func TestUploadImageContentRejects(t *testing.T) {
+	service := NewStorageService(NewMemoryBackend("https://cdn.example.com/media"))
+	service.maxPixels = 100 * 100
+
+	tests := []struct {
+		name     string
+		content  []byte
+		filename string
+		want     error
+	}{
+		{"html disguised as an image", []byte("<html><script></script></html>"), "photo.png", ErrFileTypeNotAllowed},
+		{"extension that doesn't match", testPNG(t, 10, 10), "photo.jpg", ErrFileTypeNotAllowed},
+		{"too many pixels", testPNG(t, 200, 100), "photo.png", ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.UploadImageContent(tt.content, tt.filename, "gallery"); !errors.Is(err, tt.want) {
+				t.Errorf("UploadImageContent() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
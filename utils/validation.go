@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IsValidURL accepts absolute http(s) URLs, mailto:/tel: links and site-relative paths ("/news")
+func IsValidURL(value string) bool {
+	if strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//") {
+		return true
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		return parsed.Host != ""
+	case "mailto", "tel":
+		return parsed.Opaque != ""
+	}
+	return false
+}